@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"main/internal/megaeth"
+	"main/pkg/global"
+	"main/pkg/utils"
+)
+
+// commands are run instead of the interactive menu when passed as the first argument
+var commands = map[string]func(args []string) error{
+	"allowlist-root": allowlistRootCommand,
+	"balances":       balancesCommand,
+	"config":         configCommand,
+}
+
+func runCommand(args []string) {
+	command, ok := commands[args[0]]
+	if !ok {
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Printf("Unknown command: %s\n\nAvailable commands:\n", args[0])
+		for _, name := range names {
+			fmt.Printf("  %s\n", name)
+		}
+		os.Exit(2)
+	}
+
+	if err := command(args[1:]); err != nil {
+		fmt.Printf("%s: %s\n", args[0], err)
+		os.Exit(1)
+	}
+}
+
+// allowlistRootCommand prints the Merkle root of an allowlist CSV to compare it with the drop's claim condition
+func allowlistRootCommand(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: allowlist-root <path to allowlist.csv>")
+	}
+
+	allowlist, err := utils.LoadAllowlist(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Entries: %d\nMerkle root: %s\n", len(allowlist.Entries), allowlist.Tree.Root())
+
+	return nil
+}
+
+// balancesCommand prints native balance, nonce and holdings of every account and exports them to CSV
+func balancesCommand(args []string) error {
+	threads := 10
+	if len(args) > 0 {
+		var err error
+		if threads, err = strconv.Atoi(args[0]); err != nil || threads < 1 {
+			return fmt.Errorf("usage: balances [threads]")
+		}
+	}
+
+	loadAccounts()
+
+	report, err := megaeth.CheckBalances(context.Background(), global.AccountsList, threads)
+	if err != nil {
+		return err
+	}
+
+	report.Print(os.Stdout)
+
+	csvPath := global.Config.Balances.CSVPath
+	if csvPath == "" {
+		csvPath = "balances.csv"
+	}
+
+	if err = report.WriteCSV(csvPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported to %s\n", csvPath)
+
+	return nil
+}
+
+// configCommand checks config.yaml, the accounts file and proxies.txt without running
+// anything, or prints the effective config with secrets redacted
+func configCommand(args []string) error {
+	if len(args) != 1 || (args[0] != "validate" && args[0] != "print") {
+		return fmt.Errorf("usage: config validate | config print")
+	}
+
+	config, err := utils.LoadConfig(configPath, configOverrides)
+	if err != nil {
+		return err
+	}
+	global.Config = config
+
+	if args[0] == "print" {
+		data, err := utils.RedactedConfig(*config)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+		return nil
+	}
+
+	fmt.Printf("%s: OK\n", configPath)
+
+	var problems []error
+
+	accountsPath := config.Paths.Accounts
+	if _, err = os.Stat(accountsPath); err == nil {
+		accounts, err := utils.LoadAccountsFile(accountsPath)
+		if err != nil {
+			problems = append(problems, err)
+		} else {
+			fmt.Printf("%s: %d accounts\n", accountsPath, len(accounts))
+		}
+	} else {
+		valid, keyProblems := utils.CheckKeysFile(config.Paths.PrivateKeys)
+		problems = append(problems, keyProblems...)
+		fmt.Printf("%s: %d accounts\n", config.Paths.PrivateKeys, valid)
+	}
+
+	valid, proxyProblems := utils.CheckProxiesFile(config.Paths.Proxies)
+	problems = append(problems, proxyProblems...)
+	fmt.Printf("%s: %d proxies\n", config.Paths.Proxies, valid)
+
+	return errors.Join(problems...)
+}
@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// accountStateBatchSize is the number of accounts whose state is requested in one HTTP request
+const accountStateBatchSize = 100
+
+// Batcher sends several JSON-RPC calls in a single request. *rpc.Client satisfies it.
+type Batcher interface {
+	BatchCallContext(ctx context.Context, elems []rpc.BatchElem) error
+}
+
+type wrappedBatcher struct {
+	next  Batcher
+	chain Middleware
+}
+
+// batchElemsKey carries the elements of a batch to middlewares
+type batchElemsKey struct{}
+
+// WrapBatcher layers middlewares on top of batcher. Middlewares see a batch as one
+// "batch" call, Metrics and Prometheus record its elements by their own methods.
+func WrapBatcher(batcher Batcher, middlewares ...Middleware) Batcher {
+	if len(middlewares) == 0 {
+		return batcher
+	}
+
+	return &wrappedBatcher{next: batcher, chain: chainMiddlewares(middlewares)}
+}
+
+func (w *wrappedBatcher) BatchCallContext(ctx context.Context, elems []rpc.BatchElem) error {
+	ctx = context.WithValue(ctx, batchElemsKey{}, elems)
+
+	return w.chain(ctx, "batch", func(ctx context.Context) error {
+		return w.next.BatchCallContext(ctx, elems)
+	})
+}
+
+// forEachCall calls fn for every JSON-RPC call behind one middleware invocation:
+// each element of a batch with its own error, or method itself
+func forEachCall(ctx context.Context, method string, err error, fn func(method string, err error)) {
+	elems, ok := ctx.Value(batchElemsKey{}).([]rpc.BatchElem)
+	if !ok {
+		fn(method, err)
+		return
+	}
+
+	for _, elem := range elems {
+		if err != nil {
+			fn(elem.Method, err)
+		} else {
+			fn(elem.Method, elem.Error)
+		}
+	}
+}
+
+// toCallArg converts msg into the argument of eth_call and eth_estimateGas
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["input"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	return arg
+}
+
+func nonceElem(account common.Address, result *hexutil.Uint64) rpc.BatchElem {
+	return rpc.BatchElem{Method: "eth_getTransactionCount", Args: []interface{}{account, "pending"}, Result: result}
+}
+
+func balanceElem(account common.Address, result *hexutil.Big) rpc.BatchElem {
+	return rpc.BatchElem{Method: "eth_getBalance", Args: []interface{}{account, "latest"}, Result: result}
+}
+
+func estimateElem(msg ethereum.CallMsg, result *hexutil.Uint64) rpc.BatchElem {
+	return rpc.BatchElem{Method: "eth_estimateGas", Args: []interface{}{toCallArg(msg)}, Result: result}
+}
+
+// feeElems requests the latest header and tip; decode is valid once the batch is sent
+func feeElems() (elems []rpc.BatchElem, decode func() (*FeeData, error)) {
+	var header *ethTypes.Header
+	var tipCap hexutil.Big
+
+	elems = []rpc.BatchElem{
+		{Method: "eth_getBlockByNumber", Args: []interface{}{"latest", false}, Result: &header},
+		{Method: "eth_maxPriorityFeePerGas", Result: &tipCap},
+	}
+
+	decode = func() (*FeeData, error) {
+		for _, elem := range elems {
+			if elem.Error != nil {
+				return nil, fmt.Errorf("%s: %w", elem.Method, elem.Error)
+			}
+		}
+		if header == nil {
+			return nil, ethereum.NotFound
+		}
+		return &FeeData{BaseFee: header.BaseFee, TipCap: tipCap.ToInt()}, nil
+	}
+
+	return elems, decode
+}
+
+// AccountState is the balance and pending nonce of an account
+type AccountState struct {
+	Address common.Address
+	Balance *big.Int
+	Nonce   uint64
+	Err     error
+}
+
+// LoadAccountStates fetches balances and pending nonces of addresses,
+// accountStateBatchSize accounts per request. A failed request is reported
+// through Err of its accounts and the remaining batches are still sent.
+func (r *Run) LoadAccountStates(ctx context.Context, addresses []common.Address) []AccountState {
+	states := make([]AccountState, len(addresses))
+
+	for start := 0; start < len(addresses); start += accountStateBatchSize {
+		end := min(start+accountStateBatchSize, len(addresses))
+
+		balances := make([]hexutil.Big, end-start)
+		nonces := make([]hexutil.Uint64, end-start)
+		elems := make([]rpc.BatchElem, 0, 2*(end-start))
+
+		for i, address := range addresses[start:end] {
+			elems = append(elems, balanceElem(address, &balances[i]), nonceElem(address, &nonces[i]))
+		}
+
+		err := r.Batch.BatchCallContext(ctx, elems)
+
+		for i, address := range addresses[start:end] {
+			state := AccountState{Address: address, Err: err}
+
+			if state.Err == nil {
+				state.Err = elems[2*i].Error
+			}
+			if state.Err == nil {
+				state.Err = elems[2*i+1].Error
+			}
+			if state.Err == nil {
+				state.Balance = balances[i].ToInt()
+				state.Nonce = uint64(nonces[i])
+			}
+
+			states[start+i] = state
+		}
+	}
+
+	return states
+}
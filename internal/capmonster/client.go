@@ -0,0 +1,171 @@
+package capmonster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"main/pkg/utils"
+)
+
+const (
+	DefaultBaseURL = "https://api.capmonster.cloud"
+	DefaultTimeout = 30 * time.Second
+)
+
+// UserAgent is the browser the captcha is solved as
+const UserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/128.0.0.0 Safari/537.36"
+
+// APIError is an error reported by Capmonster in errorId / errorCode
+type APIError struct {
+	Code        string
+	Description string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("capmonster error %s: %s", e.Code, e.Description)
+}
+
+// StatusError is a response with an unexpected HTTP status
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("capmonster responded with status %d", e.StatusCode)
+}
+
+type baseResponse struct {
+	ErrorID          int    `json:"errorId"`
+	ErrorCode        string `json:"errorCode"`
+	ErrorDescription string `json:"errorDescription"`
+}
+
+func (r baseResponse) err() error {
+	if r.ErrorID == 0 {
+		return nil
+	}
+	return &APIError{Code: r.ErrorCode, Description: r.ErrorDescription}
+}
+
+// TurnstileTask is a Cloudflare Turnstile captcha to solve
+type TurnstileTask struct {
+	Type       string `json:"type"`
+	WebsiteURL string `json:"websiteURL"`
+	WebsiteKey string `json:"websiteKey"`
+	UserAgent  string `json:"userAgent"`
+}
+
+// TaskResult is the state of a task; Token is set once Status is "ready"
+type TaskResult struct {
+	Status string
+	Token  string
+}
+
+// Ready reports whether the captcha is solved
+func (r *TaskResult) Ready() bool {
+	return r.Status == "ready"
+}
+
+// Client calls the Capmonster API through one HTTP client (and its proxy)
+type Client struct {
+	apiKey  string
+	baseURL string
+	timeout time.Duration
+	http    *fasthttp.Client
+}
+
+// New returns a client of the API at baseURL, DefaultBaseURL when empty
+func New(http *fasthttp.Client, apiKey string, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{apiKey: apiKey, baseURL: strings.TrimSuffix(baseURL, "/"), timeout: DefaultTimeout, http: http}
+}
+
+func (c *Client) post(ctx context.Context, method string, body interface{}, out interface{}) error {
+	resp, err := utils.PostJSON(ctx, c.http, c.baseURL+"/"+method, nil, c.timeout, body)
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(resp.Body, out); err != nil {
+		if resp.StatusCode != fasthttp.StatusOK {
+			return &StatusError{StatusCode: resp.StatusCode}
+		}
+		return fmt.Errorf("capmonster response is not JSON: %w", err)
+	}
+
+	return nil
+}
+
+// Balance returns the account balance in USD
+func (c *Client) Balance(ctx context.Context) (float64, error) {
+	var resp struct {
+		baseResponse
+		Balance float64 `json:"balance"`
+	}
+
+	if err := c.post(ctx, "getBalance", map[string]string{"clientKey": c.apiKey}, &resp); err != nil {
+		return 0, err
+	}
+
+	return resp.Balance, resp.err()
+}
+
+// CreateTurnstileTask submits a Turnstile captcha of websiteURL and returns the task ID
+func (c *Client) CreateTurnstileTask(ctx context.Context, websiteURL, websiteKey string) (int64, error) {
+	request := struct {
+		ClientKey string        `json:"clientKey"`
+		Task      TurnstileTask `json:"task"`
+	}{
+		ClientKey: c.apiKey,
+		Task: TurnstileTask{
+			Type:       "TurnstileTask",
+			WebsiteURL: websiteURL,
+			WebsiteKey: websiteKey,
+			UserAgent:  UserAgent,
+		},
+	}
+
+	var resp struct {
+		baseResponse
+		TaskID int64 `json:"taskId"`
+	}
+
+	if err := c.post(ctx, "createTask", request, &resp); err != nil {
+		return 0, err
+	}
+
+	return resp.TaskID, resp.err()
+}
+
+// TaskResult returns the state of task taskID
+func (c *Client) TaskResult(ctx context.Context, taskID int64) (*TaskResult, error) {
+	request := struct {
+		ClientKey string `json:"clientKey"`
+		TaskID    int64  `json:"taskId"`
+	}{ClientKey: c.apiKey, TaskID: taskID}
+
+	var resp struct {
+		baseResponse
+		Status   string `json:"status"`
+		Solution struct {
+			Token string `json:"token"`
+		} `json:"solution"`
+	}
+
+	if err := c.post(ctx, "getTaskResult", request, &resp); err != nil {
+		return nil, err
+	}
+
+	if err := resp.err(); err != nil {
+		return nil, err
+	}
+
+	return &TaskResult{Status: resp.Status, Token: resp.Solution.Token}, nil
+}
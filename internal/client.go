@@ -5,22 +5,73 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net/url"
+	"sync"
+	"time"
 
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	log "github.com/sirupsen/logrus"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"main/pkg/types"
 	"main/pkg/global"
+	"main/pkg/metrics"
+	"main/pkg/utils"
 )
 
 type Client struct {
-	Rpc *ethclient.Client
+	Rpc RPC
+	Run *Run
+	Signer Signer
 	Account *types.AccountData
 }
 
+const receiptPollInterval = 2 * time.Second
+
+var (
+	limiter     *RateLimiter
+	limiterOnce sync.Once
+)
+
+// NewClient binds an account and its signer to the run's RPC
+func NewClient(run *Run, signer Signer, accountData *types.AccountData) *Client {
+	return &Client{Rpc: run.Rpc, Run: run, Signer: signer, Account: accountData}
+}
+
+// middlewares builds the decorator chain configured in config.yaml for rpcURL.
+// The rate limiter is shared between all clients of the run. Retry is the
+// outermost layer, so every attempt takes a limiter token and is recorded.
+func middlewares(rpcURL string) []Middleware {
+	// only the host is exported, paths of hosted RPC often carry an API key
+	endpoint := rpcURL
+	if parsed, err := url.Parse(rpcURL); err == nil && parsed.Host != "" {
+		endpoint = parsed.Host
+	}
+
+	var chain []Middleware
+
+	if global.Config.RPC.RetryAttempts > 1 {
+		chain = append(chain, Retry(
+			global.Config.RPC.RetryAttempts,
+			time.Duration(global.Config.RPC.RetryDelay)*time.Second,
+		))
+	}
+
+	chain = append(chain, Logging(), Metrics(DefaultRPCStats), Prometheus(endpoint))
+
+	if global.Config.RPC.RequestsPerSecond > 0 {
+		limiterOnce.Do(func() {
+			limiter = NewRateLimiter(global.Config.RPC.RequestsPerSecond)
+		})
+		chain = append(chain, RateLimit(limiter))
+	}
+
+	return chain
+}
+
 func (c *Client) GetNonce() (uint64, error) {
 	ctx := context.Background()
 	return c.Rpc.PendingNonceAt(ctx, c.Account.AccountAddress)
@@ -33,7 +84,16 @@ func (c *Client) GetBalance() (*big.Int, error) {
 
 func (c *Client) GetChainID() (*big.Int, error) {
 	ctx := context.Background()
-	return c.Rpc.ChainID(ctx)
+	return c.Run.ChainID(ctx)
+}
+
+// Call executes a read-only contract call from the account's address
+func (c *Client) Call(ctx context.Context, to common.Address, data []byte) ([]byte, error) {
+	return c.Rpc.CallContract(ctx, ethereum.CallMsg{
+		From: c.Account.AccountAddress,
+		To:   &to,
+		Data: data,
+	}, nil)
 }
 
 func (c *Client) BuildTransaction(
@@ -41,17 +101,29 @@ func (c *Client) BuildTransaction(
 	data []byte,
 	value *big.Int,
 ) (*ethTypes.Transaction, error) {
-	ctx := context.Background()
-	nonce, err := c.GetNonce()
+	return c.buildTransaction(to, data, value, nil)
+}
 
-	if err != nil {
-		msg := fmt.Sprintf("[%d/%d] | %s | [BuildTransaction] | Problem with getting nonce\n",
-			global.CurrentProgress, global.TargetProgress, c.Account.AccountAddress,
-		)
-		log.Error(msg)
-		return nil, errors.New(msg)
-	}
-	
+// BuildTransactionWithNonce builds a transaction with an explicit nonce,
+// used when one account sends several transactions in a row
+func (c *Client) BuildTransactionWithNonce(
+	to string,
+	data []byte,
+	value *big.Int,
+	nonce uint64,
+) (*ethTypes.Transaction, error) {
+	return c.buildTransaction(to, data, value, &nonce)
+}
+
+// buildTransaction fetches the pending nonce (unless given), gas estimate and
+// stale fee data in a single batch request
+func (c *Client) buildTransaction(
+	to string,
+	data []byte,
+	value *big.Int,
+	nonce *uint64,
+) (*ethTypes.Transaction, error) {
+	ctx := context.Background()
 	chainID, err := c.GetChainID()
 
 	if err != nil {
@@ -63,36 +135,65 @@ func (c *Client) BuildTransaction(
 	}
 
 	toAddress := common.HexToAddress(to)
-	msg := ethereum.CallMsg{
+	callMsg := ethereum.CallMsg{
 		From:  c.Account.AccountAddress,
 		To:    &toAddress,
 		Value: value,
 		Data:  data,
 	}
 
-	header, err := c.Rpc.HeaderByNumber(ctx, nil)
-	if err != nil {
-		msg := fmt.Sprintf("[%d/%d] | %s | [BuildTransaction] | Problem with suggesting priority fee\n",
-			global.CurrentProgress, global.TargetProgress, c.Account.AccountAddress,
-		)
-		log.Error(msg)
-		return nil, errors.New(msg)
+	var gasLimit, pendingNonce hexutil.Uint64
+	elems := []rpc.BatchElem{estimateElem(callMsg, &gasLimit)}
+
+	if nonce == nil {
+		elems = append(elems, nonceElem(c.Account.AccountAddress, &pendingNonce))
 	}
-	baseFee := header.BaseFee
 
-	priorityFee, err := c.Rpc.SuggestGasTipCap(ctx)
-	if err != nil {
-		msg := fmt.Sprintf("[%d/%d] | %s | [BuildTransaction] | Problem with suggesting priority fee\n",
-			global.CurrentProgress, global.TargetProgress, c.Account.AccountAddress,
+	c.Run.feeMutex.Lock()
+	fees := c.Run.freshFees()
+	c.Run.feeMutex.Unlock()
+
+	var decodeFees func() (*FeeData, error)
+	if fees == nil {
+		var feeBatch []rpc.BatchElem
+		feeBatch, decodeFees = feeElems()
+		elems = append(elems, feeBatch...)
+	}
+
+	if err = c.Run.Batch.BatchCallContext(ctx, elems); err != nil {
+		msg := fmt.Sprintf("[%d/%d] | %s | [BuildTransaction] | Problem with batch request: %v\n",
+			global.CurrentProgress, global.TargetProgress, c.Account.AccountAddress, err,
 		)
 		log.Error(msg)
 		return nil, errors.New(msg)
 	}
 
-	maxFee := new(big.Int).Add(baseFee, priorityFee)
+	if nonce == nil {
+		if err = elems[1].Error; err != nil {
+			msg := fmt.Sprintf("[%d/%d] | %s | [BuildTransaction] | Problem with getting nonce\n",
+				global.CurrentProgress, global.TargetProgress, c.Account.AccountAddress,
+			)
+			log.Error(msg)
+			return nil, errors.New(msg)
+		}
+		nonce = (*uint64)(&pendingNonce)
+	}
 
-	gasLimit, err := c.Rpc.EstimateGas(ctx, msg)
-	if err != nil {
+	if fees == nil {
+		if fees, err = decodeFees(); err != nil {
+			msg := fmt.Sprintf("[%d/%d] | %s | [BuildTransaction] | Problem with getting fee data\n",
+				global.CurrentProgress, global.TargetProgress, c.Account.AccountAddress,
+			)
+			log.Error(msg)
+			return nil, errors.New(msg)
+		}
+
+		c.Run.feeMutex.Lock()
+		c.Run.storeFees(fees)
+		c.Run.feeMutex.Unlock()
+	}
+
+	if err = elems[0].Error; err != nil {
 		msg := fmt.Sprintf("[%d/%d] | %s | [BuildTransaction] | Problem with estimating gas\n",
 			global.CurrentProgress, global.TargetProgress, c.Account.AccountAddress,
 		)
@@ -102,10 +203,10 @@ func (c *Client) BuildTransaction(
 
 	tx := ethTypes.NewTx(&ethTypes.DynamicFeeTx{
 		ChainID:   chainID,
-		Nonce:     nonce,
-		GasFeeCap: maxFee,
-		GasTipCap: priorityFee,
-		Gas:       gasLimit,
+		Nonce:     *nonce,
+		GasFeeCap: fees.MaxFee(),
+		GasTipCap: fees.TipCap,
+		Gas:       uint64(gasLimit),
 		To:        &toAddress,
 		Value:     value,
 		Data:      data,
@@ -114,13 +215,162 @@ func (c *Client) BuildTransaction(
 	return tx, nil
 }
 
+// WaitForReceipt waits until txHash is mined or ctx is done. The receipt is
+// checked on every new head when rpc.ws_url is set and polled otherwise.
+// A reverted transaction is returned together with an error.
+func (c *Client) WaitForReceipt(ctx context.Context, txHash common.Hash) (*ethTypes.Receipt, error) {
+	return c.waitForReceipt(ctx, txHash, nil)
+}
+
+// WaitForTransaction is WaitForReceipt that also watches logs of the called
+// contract over WebSocket, catching inclusion as soon as tx emits an event
+func (c *Client) WaitForTransaction(ctx context.Context, tx *ethTypes.Transaction) (*ethTypes.Receipt, error) {
+	if c.Run.Heads == nil || tx.To() == nil || len(tx.Data()) == 0 {
+		return c.waitForReceipt(ctx, tx.Hash(), nil)
+	}
+
+	logs := make(chan ethTypes.Log, 64)
+	sub, err := c.Run.Heads.SubscribeLogs(ctx, ethereum.FilterQuery{Addresses: []common.Address{*tx.To()}}, logs)
+	if err != nil {
+		log.Debugf("[%d/%d] | %s | [WaitForTransaction] | Problem with subscribing to logs: %v\n",
+			global.CurrentProgress, global.TargetProgress, c.Account.AccountAddress, err,
+		)
+		return c.waitForReceipt(ctx, tx.Hash(), nil)
+	}
+	defer sub.Unsubscribe()
+
+	return c.waitForReceipt(ctx, tx.Hash(), logs)
+}
+
+func (c *Client) waitForReceipt(ctx context.Context, txHash common.Hash, logs <-chan ethTypes.Log) (*ethTypes.Receipt, error) {
+	// transactions are waited for right after sending, so this is their time to inclusion
+	start := time.Now()
+
+	for {
+		receipt, err := c.Rpc.TransactionReceipt(ctx, txHash)
+		if err == nil {
+			metrics.InclusionSeconds.Observe(time.Since(start).Seconds())
+
+			if receipt.Status != ethTypes.ReceiptStatusSuccessful {
+				metrics.TransactionsReverted.Inc()
+				return receipt, fmt.Errorf("transaction %s reverted", txHash)
+			}
+			metrics.TransactionsMined.Inc()
+			return receipt, nil
+		}
+
+		if !errors.Is(err, ethereum.NotFound) {
+			log.Debugf("[%d/%d] | %s | [WaitForReceipt] | Problem with getting receipt of %s: %v\n",
+				global.CurrentProgress, global.TargetProgress, c.Account.AccountAddress, txHash, err,
+			)
+		}
+
+		interval := receiptPollInterval
+		var nextHead <-chan struct{}
+		if c.Run.Heads != nil {
+			if next, active := c.Run.Heads.Next(); active {
+				nextHead, interval = next, wsReceiptPollInterval
+			}
+		}
+
+		if err = waitForWakeUp(ctx, interval, nextHead, logs, txHash); err != nil {
+			return nil, fmt.Errorf("transaction %s is not mined: %w", txHash, err)
+		}
+	}
+}
+
+// WaitForBalanceIncrease waits until the account's balance rises above before or ctx is done,
+// checking on every new head when rpc.ws_url is set and polling otherwise
+func (c *Client) WaitForBalanceIncrease(ctx context.Context, before *big.Int) (*big.Int, error) {
+	for {
+		balance, err := c.Rpc.BalanceAt(ctx, c.Account.AccountAddress, nil)
+		if err == nil && balance.Cmp(before) > 0 {
+			return balance, nil
+		}
+
+		if err != nil {
+			log.Debugf("[%d/%d] | %s | [WaitForBalanceIncrease] | Problem with getting balance: %v\n",
+				global.CurrentProgress, global.TargetProgress, c.Account.AccountAddress, err,
+			)
+		}
+
+		interval := receiptPollInterval
+		var nextHead <-chan struct{}
+		if c.Run.Heads != nil {
+			if next, active := c.Run.Heads.Next(); active {
+				nextHead, interval = next, wsReceiptPollInterval
+			}
+		}
+
+		if err = waitForWakeUp(ctx, interval, nextHead, nil, common.Hash{}); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// waitForWakeUp blocks until interval passes, the next head arrives or a log of txHash is received
+func waitForWakeUp(ctx context.Context, interval time.Duration, nextHead <-chan struct{}, logs <-chan ethTypes.Log, txHash common.Hash) error {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		case <-nextHead:
+			return nil
+		case entry := <-logs:
+			if entry.TxHash == txHash {
+				return nil
+			}
+		}
+	}
+}
+
+// SignTx signs tx with the account's signer for the current chain
+func (c *Client) SignTx(ctx context.Context, tx *ethTypes.Transaction) (*ethTypes.Transaction, error) {
+	chainID, err := c.GetChainID()
+
+	if err != nil {
+		msg := fmt.Sprintf("[%d/%d] | %s | [SignTx] | Problem with getting chainID\n",
+			global.CurrentProgress, global.TargetProgress, c.Account.AccountAddress,
+		)
+		log.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	if maxValue := c.Account.MaxValue; maxValue != nil && tx.Value().Cmp(maxValue) > 0 {
+		msg := fmt.Sprintf("[%d/%d] | %s | [SignTx] | Value %s ETH exceeds max_value %s ETH of the account\n",
+			global.CurrentProgress, global.TargetProgress, c.Account.AccountAddress,
+			utils.FormatEther(tx.Value()), utils.FormatEther(maxValue),
+		)
+		log.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	return c.Signer.SignTx(ctx, tx, chainID)
+}
+
 func GetClient(accountData *types.AccountData) (*Client, bool) {
-	ctx := context.Background()
-	rpc, err := ethclient.DialContext(ctx, "https://carrot.megaeth.com/rpc")
+	run, err := CurrentRun()
 
 	if err != nil {
+		log.Errorf("[%d/%d] | %s | [GetClient] | Problem with connecting to RPC: %v\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, err,
+		)
+		return nil, false
+	}
+
+	signer, err := NewSigner(accountData)
+
+	if err != nil {
+		log.Errorf("[%d/%d] | %s | [GetClient] | Problem with signer initialization: %v\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, err,
+		)
 		return nil, false
 	}
 
-	return &Client{Rpc: rpc, Account: accountData}, true
+	return NewClient(run, signer, accountData), true
 }
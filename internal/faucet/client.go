@@ -0,0 +1,90 @@
+package faucet
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/valyala/fasthttp"
+
+	"main/pkg/utils"
+)
+
+// Config describes the faucet endpoint of a network
+type Config struct {
+	URL     string
+	Headers map[string]string
+	Timeout time.Duration
+}
+
+// ClaimRequest is the body of a faucet claim
+type ClaimRequest struct {
+	Address common.Address `json:"addr"`
+	Token   string         `json:"token"`
+}
+
+// ClaimResponse is the faucet's verdict on a claim
+type ClaimResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// Client claims test tokens from a faucet through one HTTP client (and its proxy)
+type Client struct {
+	config Config
+	http   *fasthttp.Client
+}
+
+func New(http *fasthttp.Client, config Config) *Client {
+	return &Client{config: config, http: http}
+}
+
+// HTTP returns the HTTP client requests are sent through
+func (c *Client) HTTP() *fasthttp.Client {
+	return c.http
+}
+
+// Claim requests tokens for address with a solved captcha token. A rejected
+// claim is returned as *CooldownError, *CaptchaError or *RejectedError; a
+// failed request as *TransportError, *StatusError or *DecodeError.
+func (c *Client) Claim(ctx context.Context, address common.Address, captchaToken string) (*ClaimResponse, error) {
+	resp, err := utils.PostJSON(ctx, c.http, c.config.URL, c.config.Headers, c.config.Timeout, ClaimRequest{
+		Address: address,
+		Token:   captchaToken,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &TransportError{Err: err}
+	}
+
+	var claim ClaimResponse
+	if err = json.Unmarshal(resp.Body, &claim); err != nil {
+		if resp.StatusCode != fasthttp.StatusOK {
+			return nil, &StatusError{StatusCode: resp.StatusCode, Body: truncate(resp.Body)}
+		}
+		return nil, &DecodeError{Body: truncate(resp.Body), Err: err}
+	}
+
+	if !claim.Success {
+		if claim.Message == "" && resp.StatusCode != fasthttp.StatusOK {
+			return nil, &StatusError{StatusCode: resp.StatusCode, Body: truncate(resp.Body)}
+		}
+		return &claim, classifyRejection(claim.Message)
+	}
+
+	return &claim, nil
+}
+
+func truncate(body []byte) string {
+	const limit = 200
+
+	text := strings.TrimSpace(string(body))
+	if len(text) > limit {
+		return text[:limit] + "..."
+	}
+	return text
+}
@@ -0,0 +1,124 @@
+package faucet
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TransportError is a request that never got a response: dial, proxy or timeout failure
+type TransportError struct {
+	Err error
+}
+
+func (e *TransportError) Error() string { return fmt.Sprintf("faucet request failed: %v", e.Err) }
+func (e *TransportError) Unwrap() error { return e.Err }
+
+// StatusError is a response with an unexpected HTTP status and no faucet verdict
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("faucet responded with status %d: %s", e.StatusCode, e.Body)
+}
+
+// DecodeError is a response body that is not the faucet's JSON
+type DecodeError struct {
+	Body string
+	Err  error
+}
+
+func (e *DecodeError) Error() string { return fmt.Sprintf("faucet response is not JSON: %v", e.Err) }
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// CaptchaError is a claim rejected because the captcha token is invalid or expired
+type CaptchaError struct {
+	Message string
+}
+
+func (e *CaptchaError) Error() string { return "faucet rejected captcha: " + e.Message }
+
+// CooldownError is a claim rejected because the address claimed recently.
+// Wait is zero when the faucet did not say how long to wait.
+type CooldownError struct {
+	Message string
+	Wait    time.Duration
+}
+
+func (e *CooldownError) Error() string { return "faucet cooldown: " + e.Message }
+
+// RejectedError is any other claim the faucet refused
+type RejectedError struct {
+	Message string
+}
+
+func (e *RejectedError) Error() string { return "faucet rejected claim: " + e.Message }
+
+// Retryable reports whether the same claim may succeed when sent again
+func Retryable(err error) bool {
+	switch err.(type) {
+	case *TransportError, *StatusError, *DecodeError:
+		return true
+	}
+	return false
+}
+
+var (
+	durationRegexp = regexp.MustCompile(`(?i)\b(\d+)\s*(days?|d|hours?|hrs?|h|minutes?|mins?|m|seconds?|secs?|s)\b`)
+	cooldownRegexp = regexp.MustCompile(`(?i)cooldown|wait|again|already|too many|once per|rate limit`)
+	// claimedRegexp marks rejections that mean a cooldown even without a duration
+	claimedRegexp = regexp.MustCompile(`(?i)cooldown|already claimed|once per`)
+	captchaRegexp = regexp.MustCompile(`(?i)captcha|turnstile`)
+)
+
+// parseCooldown extracts the remaining wait from a rejection such as
+// "Please wait 23 hours 10 minutes" or "already claimed, try again in 5h"
+func parseCooldown(message string) (time.Duration, bool) {
+	if !cooldownRegexp.MatchString(message) {
+		return 0, false
+	}
+
+	var wait time.Duration
+	for _, match := range durationRegexp.FindAllStringSubmatch(message, -1) {
+		value, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+
+		var unit time.Duration
+		switch strings.ToLower(match[2])[0] {
+		case 'd':
+			unit = 24 * time.Hour
+		case 'h':
+			unit = time.Hour
+		case 'm':
+			unit = time.Minute
+		case 's':
+			unit = time.Second
+		}
+		wait += time.Duration(value) * unit
+	}
+
+	if wait == 0 && !claimedRegexp.MatchString(message) {
+		return 0, false
+	}
+
+	return wait, true
+}
+
+// classifyRejection turns the message of an unsuccessful claim into its error type
+func classifyRejection(message string) error {
+	if wait, ok := parseCooldown(message); ok {
+		return &CooldownError{Message: message, Wait: wait}
+	}
+
+	if captchaRegexp.MatchString(message) {
+		return &CaptchaError{Message: message}
+	}
+
+	return &RejectedError{Message: message}
+}
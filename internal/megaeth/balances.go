@@ -0,0 +1,373 @@
+package megaeth
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	log "github.com/sirupsen/logrus"
+
+	"main/internal"
+	"main/pkg/global"
+	accTypes "main/pkg/types"
+	"main/pkg/utils"
+)
+
+type NFTColumn struct {
+	Address common.Address
+	Name    string
+}
+
+type TokenColumn struct {
+	Address  common.Address
+	Symbol   string
+	Decimals int
+}
+
+// AccountHoldings is the state of a single wallet; NFTs and Tokens follow the report columns
+type AccountHoldings struct {
+	Address common.Address
+	Balance *big.Int
+	Nonce   uint64
+	NFTs    []*big.Int
+	Tokens  []*big.Int
+	Err     error
+}
+
+type BalancesReport struct {
+	NFTs   []NFTColumn
+	Tokens []TokenColumn
+	Rows   []AccountHoldings
+}
+
+// balanceColumns resolves NFT and token columns from contracts of the active network.
+// Without contracts.balance_nfts every claim drop is checked.
+func balanceColumns(ctx context.Context, client *internal.Client) ([]NFTColumn, []TokenColumn, error) {
+	var nfts []NFTColumn
+
+	if len(global.Network.Contracts.BalanceNFTs) == 0 {
+		for _, drop := range global.Network.Drops {
+			nfts = append(nfts, NFTColumn{Address: common.HexToAddress(drop.Contract), Name: drop.Name})
+		}
+	}
+
+	for _, contract := range global.Network.Contracts.BalanceNFTs {
+		if !common.IsHexAddress(contract) {
+			return nil, nil, fmt.Errorf("invalid contracts.balance_nfts address %q of network %s", contract, global.NetworkName)
+		}
+
+		column := NFTColumn{Address: common.HexToAddress(contract), Name: contract}
+		for _, drop := range global.Network.Drops {
+			if common.HexToAddress(drop.Contract) == column.Address {
+				column.Name = drop.Name
+			}
+		}
+		nfts = append(nfts, column)
+	}
+
+	erc20ABI, err := utils.LoadABI(filepath.Join("abi", "erc20.json"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tokens []TokenColumn
+	for _, token := range global.Network.Contracts.Tokens {
+		if !common.IsHexAddress(token) {
+			return nil, nil, fmt.Errorf("invalid contracts.tokens address %q of network %s", token, global.NetworkName)
+		}
+
+		column := TokenColumn{Address: common.HexToAddress(token), Symbol: token, Decimals: 18}
+
+		if out, err := callView(ctx, client, erc20ABI, column.Address, "symbol"); err == nil {
+			column.Symbol = out[0].(string)
+		}
+		if out, err := callView(ctx, client, erc20ABI, column.Address, "decimals"); err == nil {
+			column.Decimals = int(out[0].(uint8))
+		}
+
+		tokens = append(tokens, column)
+	}
+
+	return nfts, tokens, nil
+}
+
+// batchHoldings reads native, NFT and token balances of every account through
+// Multicall3. Values of failed calls are left nil for accountHoldings to retry.
+func batchHoldings(ctx context.Context, run *internal.Run, accounts []accTypes.AccountData, report *BalancesReport) error {
+	multicall, err := run.Multicall()
+	if err != nil {
+		return err
+	}
+
+	erc721ABI, err := utils.LoadABI(filepath.Join("abi", "erc721.json"))
+	if err != nil {
+		return err
+	}
+
+	erc20ABI, err := utils.LoadABI(filepath.Join("abi", "erc20.json"))
+	if err != nil {
+		return err
+	}
+
+	perAccount := 1 + len(report.NFTs) + len(report.Tokens)
+	calls := make([]internal.Call3, 0, len(accounts)*perAccount)
+
+	for _, account := range accounts {
+		calls = append(calls, multicall.EthBalanceCall(account.AccountAddress))
+
+		for _, column := range report.NFTs {
+			call, err := internal.NewCall(erc721ABI, column.Address, "balanceOf", account.AccountAddress)
+			if err != nil {
+				return err
+			}
+			calls = append(calls, call)
+		}
+
+		for _, column := range report.Tokens {
+			call, err := internal.NewCall(erc20ABI, column.Address, "balanceOf", account.AccountAddress)
+			if err != nil {
+				return err
+			}
+			calls = append(calls, call)
+		}
+	}
+
+	results, err := multicall.Aggregate3(ctx, calls)
+	if err != nil {
+		return err
+	}
+
+	decodeBalance := func(contractABI abi.ABI, result internal.Call3Result) *big.Int {
+		if !result.Success {
+			return nil
+		}
+		out, err := contractABI.Unpack("balanceOf", result.ReturnData)
+		if err != nil {
+			return nil
+		}
+		return abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	}
+
+	for i, account := range accounts {
+		row := results[i*perAccount : (i+1)*perAccount]
+
+		holdings := AccountHoldings{
+			Address: account.AccountAddress,
+			Balance: multicall.DecodeEthBalance(row[0]),
+		}
+		for j := range report.NFTs {
+			holdings.NFTs = append(holdings.NFTs, decodeBalance(erc721ABI, row[1+j]))
+		}
+		for j := range report.Tokens {
+			holdings.Tokens = append(holdings.Tokens, decodeBalance(erc20ABI, row[1+len(report.NFTs)+j]))
+		}
+
+		report.Rows[i] = holdings
+	}
+
+	return nil
+}
+
+// accountHoldings completes holdings with values batchHoldings and the
+// batched account state could not read
+func accountHoldings(ctx context.Context, client *internal.Client, report *BalancesReport, holdings AccountHoldings, state internal.AccountState) AccountHoldings {
+	holdings.Address = client.Account.AccountAddress
+
+	erc721ABI, err := utils.LoadABI(filepath.Join("abi", "erc721.json"))
+	if err != nil {
+		holdings.Err = err
+		return holdings
+	}
+
+	if state.Err == nil {
+		if holdings.Balance == nil {
+			holdings.Balance = state.Balance
+		}
+		holdings.Nonce = state.Nonce
+	} else {
+		if holdings.Balance == nil {
+			if holdings.Balance, err = client.Rpc.BalanceAt(ctx, holdings.Address, nil); err != nil {
+				holdings.Err = fmt.Errorf("balance: %w", err)
+				return holdings
+			}
+		}
+
+		if holdings.Nonce, err = client.Rpc.PendingNonceAt(ctx, holdings.Address); err != nil {
+			holdings.Err = fmt.Errorf("nonce: %w", err)
+			return holdings
+		}
+	}
+
+	if holdings.NFTs == nil {
+		holdings.NFTs = make([]*big.Int, len(report.NFTs))
+	}
+	for i, column := range report.NFTs {
+		if holdings.NFTs[i] != nil {
+			continue
+		}
+		if out, err := callView(ctx, client, erc721ABI, column.Address, "balanceOf", holdings.Address); err == nil {
+			holdings.NFTs[i] = abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+		}
+	}
+
+	if holdings.Tokens == nil {
+		holdings.Tokens = make([]*big.Int, len(report.Tokens))
+	}
+	for i, column := range report.Tokens {
+		if holdings.Tokens[i] != nil {
+			continue
+		}
+		if balance, err := tokenBalance(ctx, client, column.Address); err == nil {
+			holdings.Tokens[i] = balance
+		}
+	}
+
+	return holdings
+}
+
+// CheckBalances queries native balance, nonce and configured NFT and token holdings of accounts.
+// Balances are batched through Multicall3 and nonces through JSON-RPC batches;
+// failed reads are retried one by one in threads goroutines.
+func CheckBalances(ctx context.Context, accounts []accTypes.AccountData, threads int) (*BalancesReport, error) {
+	run, err := internal.CurrentRun()
+	if err != nil {
+		return nil, err
+	}
+
+	if threads < 1 {
+		threads = 1
+	}
+
+	report := &BalancesReport{Rows: make([]AccountHoldings, len(accounts))}
+
+	if len(accounts) > 0 {
+		if report.NFTs, report.Tokens, err = balanceColumns(ctx, internal.NewClient(run, nil, &accounts[0])); err != nil {
+			return nil, err
+		}
+
+		if err = batchHoldings(ctx, run, accounts, report); err != nil {
+			log.Warnf("[Balances] | Multicall3 is unavailable, falling back to per-account calls: %v\n", err)
+		}
+	}
+
+	addresses := make([]common.Address, len(accounts))
+	for i, account := range accounts {
+		addresses[i] = account.AccountAddress
+	}
+	states := run.LoadAccountStates(ctx, addresses)
+
+	indexes := make(chan int)
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				client := internal.NewClient(run, nil, &accounts[index])
+				report.Rows[index] = accountHoldings(ctx, client, report, report.Rows[index], states[index])
+			}
+		}()
+	}
+
+	for i := range accounts {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return report, nil
+}
+
+func formatHolding(value *big.Int, decimals int) string {
+	if value == nil {
+		return "error"
+	}
+	return utils.FormatUnits(value, decimals)
+}
+
+func (r *BalancesReport) header() []string {
+	header := []string{"Address", "ETH", "Nonce"}
+	for _, column := range r.NFTs {
+		header = append(header, column.Name)
+	}
+	for _, column := range r.Tokens {
+		header = append(header, column.Symbol)
+	}
+	return append(header, "Error")
+}
+
+func (r *BalancesReport) records() [][]string {
+	records := make([][]string, 0, len(r.Rows))
+
+	columns := len(r.header())
+
+	for _, row := range r.Rows {
+		if row.Err != nil {
+			record := []string{row.Address.Hex()}
+			for len(record) < columns-1 {
+				record = append(record, "")
+			}
+			records = append(records, append(record, row.Err.Error()))
+			continue
+		}
+
+		record := []string{row.Address.Hex(), utils.FormatEther(row.Balance), fmt.Sprint(row.Nonce)}
+		for _, value := range row.NFTs {
+			record = append(record, formatHolding(value, 0))
+		}
+		for i, value := range row.Tokens {
+			record = append(record, formatHolding(value, r.Tokens[i].Decimals))
+		}
+		records = append(records, append(record, ""))
+	}
+
+	return records
+}
+
+// Print writes the report as a table with totals of ETH
+func (r *BalancesReport) Print(w io.Writer) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(table, strings.Join(r.header(), "\t"))
+	for _, record := range r.records() {
+		fmt.Fprintln(table, strings.Join(record, "\t"))
+	}
+	table.Flush()
+
+	total := big.NewInt(0)
+	for _, row := range r.Rows {
+		if row.Balance != nil {
+			total.Add(total, row.Balance)
+		}
+	}
+	fmt.Fprintf(w, "\nWallets: %d | Total: %s ETH\n", len(r.Rows), utils.FormatEther(total))
+}
+
+// WriteCSV exports the report to path
+func (r *BalancesReport) WriteCSV(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err = writer.Write(r.header()); err != nil {
+		return err
+	}
+	if err = writer.WriteAll(r.records()); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
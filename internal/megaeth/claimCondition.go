@@ -0,0 +1,101 @@
+package megaeth
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+
+	"main/internal"
+	"main/pkg/utils"
+)
+
+// ClaimCondition mirrors IClaimCondition.ClaimCondition of claim-based drops
+type ClaimCondition struct {
+	StartTimestamp         *big.Int
+	MaxClaimableSupply     *big.Int
+	SupplyClaimed          *big.Int
+	QuantityLimitPerWallet *big.Int
+	MerkleRoot             [32]byte
+	PricePerToken          *big.Int
+	Currency               common.Address
+	Metadata               string
+}
+
+// ActiveClaim is the active claim condition together with the account's usage of it
+type ActiveClaim struct {
+	ID              *big.Int
+	Condition       ClaimCondition
+	ClaimedByWallet *big.Int
+}
+
+// RemainingSupply is the number of tokens left in the phase, nil when supply is unlimited
+func (a *ActiveClaim) RemainingSupply() *big.Int {
+	if a.Condition.MaxClaimableSupply.Cmp(math.MaxBig256) == 0 {
+		return nil
+	}
+
+	remaining := new(big.Int).Sub(a.Condition.MaxClaimableSupply, a.Condition.SupplyClaimed)
+	if remaining.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return remaining
+}
+
+// RemainingForWallet is the number of tokens the wallet may still claim given limit
+func (a *ActiveClaim) RemainingForWallet(limit *big.Int) *big.Int {
+	remaining := new(big.Int).Sub(limit, a.ClaimedByWallet)
+	if remaining.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return remaining
+}
+
+func callView(ctx context.Context, client *internal.Client, contractABI abi.ABI, contract common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.Call(ctx, contract, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return contractABI.Unpack(method, result)
+}
+
+// getActiveClaim reads the active claim condition of the drop and how much of it the account used
+func getActiveClaim(ctx context.Context, client *internal.Client, contract common.Address) (*ActiveClaim, error) {
+	contractABI, err := utils.LoadABI(filepath.Join("abi", "claim.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := callView(ctx, client, contractABI, contract, "getActiveClaimConditionId")
+	if err != nil {
+		return nil, err
+	}
+	conditionID := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+
+	out, err = callView(ctx, client, contractABI, contract, "getClaimConditionById", conditionID)
+	if err != nil {
+		return nil, err
+	}
+	condition := *abi.ConvertType(out[0], new(ClaimCondition)).(*ClaimCondition)
+
+	out, err = callView(ctx, client, contractABI, contract, "getSupplyClaimedByWallet", conditionID, client.Account.AccountAddress)
+	if err != nil {
+		return nil, err
+	}
+	claimedByWallet := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+
+	return &ActiveClaim{
+		ID:              conditionID,
+		Condition:       condition,
+		ClaimedByWallet: claimedByWallet,
+	}, nil
+}
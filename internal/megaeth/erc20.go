@@ -0,0 +1,117 @@
+package megaeth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	log "github.com/sirupsen/logrus"
+
+	"main/internal"
+	"main/pkg/global"
+	"main/pkg/utils"
+)
+
+// approvalTimeout limits how long a claim waits for its approval to be mined
+const approvalTimeout = 3 * time.Minute
+
+func tokenBalance(ctx context.Context, client *internal.Client, token common.Address) (*big.Int, error) {
+	erc20ABI, err := utils.LoadABI(filepath.Join("abi", "erc20.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := callView(ctx, client, erc20ABI, token, "balanceOf", client.Account.AccountAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return abi.ConvertType(out[0], new(big.Int)).(*big.Int), nil
+}
+
+func tokenAllowance(ctx context.Context, client *internal.Client, token common.Address, spender common.Address) (*big.Int, error) {
+	erc20ABI, err := utils.LoadABI(filepath.Join("abi", "erc20.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := callView(ctx, client, erc20ABI, token, "allowance", client.Account.AccountAddress, spender)
+	if err != nil {
+		return nil, err
+	}
+
+	return abi.ConvertType(out[0], new(big.Int)).(*big.Int), nil
+}
+
+// ensureTokenPayment checks that the account holds amount of token and approves
+// spender when the current allowance is short. With exact set only amount is
+// approved, otherwise the allowance is made unlimited.
+func ensureTokenPayment(
+	ctx context.Context,
+	client *internal.Client,
+	op *operation,
+	displayName string,
+	token common.Address,
+	spender common.Address,
+	amount *big.Int,
+	exact bool,
+) error {
+	accountAddress := client.Account.AccountAddress
+
+	balance, err := tokenBalance(ctx, client, token)
+	if err != nil {
+		return fmt.Errorf("problem with getting %s balance: %w", token, err)
+	}
+
+	if balance.Cmp(amount) < 0 {
+		return fmt.Errorf("insufficient %s balance: %v < %v", token, balance, amount)
+	}
+
+	allowance, err := tokenAllowance(ctx, client, token, spender)
+	if err != nil {
+		return fmt.Errorf("problem with getting %s allowance: %w", token, err)
+	}
+
+	if allowance.Cmp(amount) >= 0 {
+		return nil
+	}
+
+	approveAmount := math.MaxBig256
+	if exact {
+		approveAmount = amount
+	}
+
+	log.Infof("[%d/%d] | %s | [%s] | Approve %v of %s for %s (allowance: %v)\n",
+		global.CurrentProgress, global.TargetProgress, accountAddress, displayName, approveAmount, token, spender, allowance,
+	)
+
+	erc20ABI, err := utils.LoadABI(filepath.Join("abi", "erc20.json"))
+	if err != nil {
+		return err
+	}
+
+	data, err := erc20ABI.Pack("approve", spender, approveAmount)
+	if err != nil {
+		return fmt.Errorf("problem with encoding approve: %w", err)
+	}
+
+	tx, err := sendTransaction(ctx, client, displayName, token.Hex(), data, big.NewInt(0))
+	if err != nil {
+		return err
+	}
+	op.add("approve", tx.Hash())
+
+	waitCtx, cancel := context.WithTimeout(ctx, approvalTimeout)
+	defer cancel()
+
+	if _, err = client.WaitForTransaction(waitCtx, tx); err != nil {
+		return fmt.Errorf("approval failed: %w", err)
+	}
+
+	return nil
+}
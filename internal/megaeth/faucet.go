@@ -2,328 +2,216 @@ package megaeth
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"time"
 
 	log "github.com/sirupsen/logrus"
-	"github.com/tidwall/gjson"
-	"github.com/valyala/fasthttp"
 
+	"main/internal"
+	"main/internal/capmonster"
+	"main/internal/faucet"
 	"main/pkg/global"
+	"main/pkg/metrics"
+	"main/pkg/state"
 	"main/pkg/types"
 	"main/pkg/utils"
 )
 
-func checkBalance(ctx context.Context, accountData types.AccountData) bool {
-	var err error
-	var result bool
-	var url = "https://api.capmonster.cloud/getBalance"
+const (
+	// minCapmonsterBalance is the balance in USD required to pay for a captcha
+	minCapmonsterBalance = 0.01
 
-	maxAttempts := 5
-	retryDelay := 3 * time.Second
+	faucetMaxAttempts = 5
+	faucetRetryDelay  = 3 * time.Second
+	faucetStepTimeout = 30 * time.Second
+)
 
-	payload := map[string]interface{}{
-		"clientKey": global.Config.CapmonsterAPIKey,
+// faucetConfig is the faucet endpoint of the active network
+func faucetConfig() faucet.Config {
+	return faucet.Config{
+		URL:     global.Network.Faucet.URL,
+		Headers: global.Network.Faucet.Headers,
+		Timeout: time.Duration(global.Config.Faucet.RequestTimeout) * time.Second,
 	}
+}
 
-	ctx, cancel := context.WithTimeout(ctx, 30 * time.Second)
-	defer cancel()
-
-	for i := 1; i < maxAttempts + 1; i++ {
-		payloadBytes, _ := json.Marshal(payload)
-
-		client := utils.GetClient()
-
-		req := fasthttp.AcquireRequest()
-		defer fasthttp.ReleaseRequest(req)
+// waitRetry sleeps faucetRetryDelay unless ctx is done first
+func waitRetry(ctx context.Context, accountData types.AccountData, step string, attempt int) bool {
+	select {
+	case <-ctx.Done():
+		log.Warnf("[%d/%d] | %s | [%s] | Attempt: [%d/%d] | Cancelled (timeout reached)\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, step, attempt, faucetMaxAttempts,
+		)
+		return false
+	case <-time.After(faucetRetryDelay):
+		log.Infof("[%d/%d] | %s | [%s] | Attempt: [%d/%d] | Retry after %v seconds ...\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, step, attempt, faucetMaxAttempts, faucetRetryDelay.Seconds(),
+		)
+		return true
+	}
+}
 
-		req.SetRequestURI(url)
-		req.Header.SetMethod("POST")
-		req.SetBody(payloadBytes)
+func checkBalance(ctx context.Context, solver *capmonster.Client, accountData types.AccountData) bool {
+	ctx, cancel := context.WithTimeout(ctx, faucetStepTimeout)
+	defer cancel()
 
-		resp := fasthttp.AcquireResponse()
-		defer fasthttp.ReleaseResponse(resp)
+	for i := 1; i <= faucetMaxAttempts; i++ {
+		balance, err := solver.Balance(ctx)
 
-		if err = client.Do(req, resp); err != nil {
-			log.Warnf("[%d/%d] | %s | [checkBalance] | Attempt: [%d/%d] | Failed to do request: %v\n",
-				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, err,
+		if err != nil {
+			log.Warnf("[%d/%d] | %s | [checkBalance] | Attempt: [%d/%d] | Problem with checking balance: %v\n",
+				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, faucetMaxAttempts, err,
+			)
+		} else if balance <= minCapmonsterBalance {
+			log.Warnf("[%d/%d] | %s | [checkBalance] | Attempt: [%d/%d] | Insufficient balance on Capmonster: %v\n",
+				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, faucetMaxAttempts, balance,
 			)
 		} else {
-			respStatus := resp.StatusCode()
-			respBody := resp.Body()
-			json := string(respBody)
-	
-			errorId := gjson.Get(json, "errorId").Int()
-	
-			if respStatus != fasthttp.StatusOK {
-				log.Warnf("[%d/%d] | %s | [checkBalance] | Attempt: [%d/%d] | Wrong Response Status Code: %v\n",
-					global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, respStatus,
-				)
-			}
-
-			if errorId == 0 {
-				balance := gjson.Get(json, "balance").Float()
-				if balance <= 0.01 {
-					log.Warnf("[%d/%d] | %s | [checkBalance] | Attempt: [%d/%d] | Insufficient balance on Capmonster: %v\n",
-						global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, balance,
-					)
-				} else {
-					log.Printf("[%d/%d] | %s | [checkBalance] |Attempt: [%d/%d] | You have enough balance %0.2f (>0.01)\n",
-						global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, balance,
-					)
-					result = true
-					break
-				}
-			} else {
-				log.Warnf("[%d/%d] | %s | [checkBalance] | Attempt: [%d/%d] | External error while checking balance: %v\n",
-					global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, err,
-				)
-			}
+			log.Printf("[%d/%d] | %s | [checkBalance] | Attempt: [%d/%d] | You have enough balance %0.2f (>%v)\n",
+				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, faucetMaxAttempts, balance, minCapmonsterBalance,
+			)
+			return true
 		}
 
-		select {
-		case <-ctx.Done():
-			log.Warnf("[%d/%d] | %s | [checkBalance] | Attempt: [%d/%d] | Cancelled (timeout reached)\n",
-				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts,
-			)
+		if !waitRetry(ctx, accountData, "checkBalance", i) {
 			return false
-		case <-time.After(retryDelay):
-			log.Infof("[%d/%d] | %s | [checkBalance] | Attempt: [%d/%d] | Retry after %f sec ...\n",
-				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, retryDelay.Seconds(),
-			)
 		}
 	}
-	return result
+	return false
 }
 
-func createTask(ctx context.Context, accountData types.AccountData) (int, error) {
+func createTask(ctx context.Context, solver *capmonster.Client, accountData types.AccountData) (int64, error) {
 	var err error
-	var errorCode int = -1
-	var url = "https://api.capmonster.cloud/createTask"
 
-	maxAttempts := 5
-	retryDelay := 3 * time.Second
-
-	ctx, cancel := context.WithTimeout(ctx, 30 * time.Second)
+	ctx, cancel := context.WithTimeout(ctx, faucetStepTimeout)
 	defer cancel()
 
-	payload := map[string]interface{}{
-		"clientKey": global.Config.CapmonsterAPIKey,
-		"task": map[string]interface{}{
-			"type":       "TurnstileTask",
-			"websiteURL": "https://testnet.megaeth.com/#1",
-			"websiteKey": "0x4AAAAAABA4JXCaw9E2Py-9",
-			"userAgent":  "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/128.0.0.0 Safari/537.36",
-		},
-	}
-
-	for i := 1; i < maxAttempts + 1; i++ {
-		payloadBytes, _ := json.Marshal(payload)
+	for i := 1; i <= faucetMaxAttempts; i++ {
+		var taskID int64
+		taskID, err = solver.CreateTurnstileTask(ctx, global.Network.Faucet.WebsiteURL, global.Network.Faucet.SiteKey)
 
-		client := utils.GetClient()
-
-		req := fasthttp.AcquireRequest()
-		defer fasthttp.ReleaseRequest(req)
-
-		req.SetRequestURI(url)
-		req.Header.SetMethod("POST")
-		req.SetBody(payloadBytes)
-
-		resp := fasthttp.AcquireResponse()
-		defer fasthttp.ReleaseResponse(resp)
-
-		if err = client.Do(req, resp); err != nil {
-			log.Warnf("[%d/%d] | %s | [createTask] | Attempt: [%d/%d] | Failed to do request: %v\n",
-				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, err,
-			)
-		} else {
-			respStatus := resp.StatusCode()
-			respBody := resp.Body()
-			json := string(respBody)
-	
-			errorId := gjson.Get(json, "errorId").Int()
-	
-			if respStatus != fasthttp.StatusOK {
-				log.Warnf("[%d/%d] | %s | [createTask] | Attempt: [%d/%d] | Wrong Response Status Code: %v\n",
-					global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, respStatus,
-				)
-			}
-	
-			if errorId == 0 {
-				return int(gjson.Get(json, "taskId").Int()), nil
-			} else {
-				errorCode = int(gjson.Get(json, "errorCode").Int())
-			}
+		if err == nil {
+			return taskID, nil
 		}
 
-		select {
-		case <-ctx.Done():
-			log.Warnf("[%d/%d] | %s | [createTask] | Attempt: [%d/%d] | Cancelled (timeout reached)\n",
-				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts,
-			)
-			return errorCode, err
-		case <-time.After(retryDelay):
-			log.Infof("[%d/%d] | %s | [createTask] | Attempt: [%d/%d] | Retry after %f sec ...\n",
-				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, retryDelay.Seconds(),
-			)
+		log.Warnf("[%d/%d] | %s | [createTask] | Attempt: [%d/%d] | Problem with creating task: %v\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, faucetMaxAttempts, err,
+		)
+
+		if !waitRetry(ctx, accountData, "createTask", i) {
+			break
 		}
 	}
-	return errorCode, err
+	return 0, err
 }
 
-func resultCaptcha(ctx context.Context, accountData types.AccountData, taskId int) (string, error) {
-	var err error
-	var url = "https://api.capmonster.cloud/getTaskResult"
-
-	maxAttempts := 5
-	retryDelay := 3 * time.Second
+func resultCaptcha(ctx context.Context, solver *capmonster.Client, accountData types.AccountData, taskID int64) (string, error) {
+	err := errors.New("captcha is not solved")
 
-	ctx, cancel := context.WithTimeout(ctx, 30 * time.Second)
+	ctx, cancel := context.WithTimeout(ctx, faucetStepTimeout)
 	defer cancel()
 
-	payload := map[string]interface{}{
-		"clientKey": global.Config.CapmonsterAPIKey,
-		"taskId":    taskId,
-	}
+	for i := 1; i <= faucetMaxAttempts; i++ {
+		result, resultErr := solver.TaskResult(ctx, taskID)
 
-	for i := 0; i < maxAttempts; i++ {
-		payloadBytes, _ := json.Marshal(payload)
-
-		client := utils.GetClient()
-
-		req := fasthttp.AcquireRequest()
-		defer fasthttp.ReleaseRequest(req)
-
-		req.SetRequestURI(url)
-		req.Header.SetMethod("POST")
-		req.SetBody(payloadBytes)
-
-		resp := fasthttp.AcquireResponse()
-		defer fasthttp.ReleaseResponse(resp)
-
-		if err = client.Do(req, resp); err != nil {
-			log.Warnf("[%d/%d] | %s | [resultCaptcha] | Attempt: [%d/%d] | Failed to do request: %v\n",
-				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, err,
+		switch {
+		case resultErr != nil:
+			err = resultErr
+			log.Warnf("[%d/%d] | %s | [resultCaptcha] | Attempt: [%d/%d] | Problem with getting task result: %v\n",
+				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, faucetMaxAttempts, resultErr,
+			)
+		case result.Ready():
+			return result.Token, nil
+		default:
+			log.Infof("[%d/%d] | %s | [resultCaptcha] | Attempt: [%d/%d] | Captcha result is still processing ...\n",
+				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, faucetMaxAttempts,
 			)
-		} else {
-			respStatus := resp.StatusCode()
-			respBody := resp.Body()
-			json := string(respBody)
-	
-			if respStatus != fasthttp.StatusOK {
-				log.Warnf("[%d/%d] | %s | [resultCaptcha] | Attempt: [%d/%d] | Wrong Response Status Code: %v\n",
-					global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, respStatus,
-				)
-			}
-	
-			status := gjson.Get(json, "status").String()
-	
-			if status == "processing" {
-				log.Infof("[%d/%d] | %s | [resultCaptcha] | Attempt: [%d/%d] | Captcha result is still processing ...\n",
-					global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts,
-				)
-				log.Infof("[%d/%d] | %s | [resultCaptcha] | Attempt: [%d/%d] | Retry after %v seconds ...\n",
-					global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, retryDelay.Seconds(),
-				)
-			} else if status == "ready" {
-				return gjson.Get(json, "solution.token").String(), nil
-			}
 		}
 
-		select {
-		case <-ctx.Done():
-			log.Warnf("[%d/%d] | %s | [resultCaptcha] | Cancelled while waiting for retry\n",
-				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress,
-			)
-			return "", err
-		case <-time.After(retryDelay):
-			log.Infof("[%d/%d] | %s | [resultCaptcha] | Attempt: [%d/%d] | Retry after %v seconds ...\n",
-				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, retryDelay.Seconds(),
-			)
+		if !waitRetry(ctx, accountData, "resultCaptcha", i) {
+			break
 		}
 	}
 	return "", err
 }
 
-func getTokenFaucet(ctx context.Context, accountData types.AccountData, token string) (bool, error) {
+// getTokenFaucet claims tokens, retrying only failures of the request itself.
+// A cooldown reported by the faucet is saved to skip the address next time.
+func getTokenFaucet(ctx context.Context, client *faucet.Client, accountData types.AccountData, token string) error {
 	var err error
-	var url = "https://carrot.megaeth.com/claim"
 
-	maxAttempts := 5
-	retryDelay := 3 * time.Second
-
-	ctx, cancel := context.WithTimeout(ctx, 30 * time.Second)
+	ctx, cancel := context.WithTimeout(ctx, faucetStepTimeout)
 	defer cancel()
 
-	payload := map[string]interface{}{
-		"addr":  accountData.AccountAddress,
-		"token": token,
-	}
-
-	for i := 0; i < maxAttempts; i++ {
-		payloadBytes, _ := json.Marshal(payload)
-
-		client := utils.GetClient()
-
-		req := fasthttp.AcquireRequest()
-		defer fasthttp.ReleaseRequest(req)
-
-		req.SetRequestURI(url)
-		req.Header.SetMethod("POST")
-		req.SetBody(payloadBytes)
+	for i := 1; i <= faucetMaxAttempts; i++ {
+		if _, err = client.Claim(ctx, accountData.AccountAddress, token); err == nil {
+			log.Infof("[%d/%d] | %s | [getTokenFaucet] | Attempt: [%d/%d] | Successfully get tokens from Faucet\n",
+				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, faucetMaxAttempts,
+			)
+			return nil
+		}
 
-		resp := fasthttp.AcquireResponse()
-		defer fasthttp.ReleaseResponse(resp)
+		var transport *faucet.TransportError
+		if errors.As(err, &transport) {
+			utils.Pool.MarkFailed(client.HTTP(), err)
+		}
 
-		if err = client.Do(req, resp); err != nil {
-			log.Warnf("[%d/%d] | %s | [getTokenFaucet] | Attempt: [%d/%d] | Failed to do request: %v\n",
-				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, err,
-			)
-		} else {
-			respStatus := resp.StatusCode()
-			respBody := resp.Body()
-			json := string(respBody)
-	
-			if respStatus != fasthttp.StatusOK {
-				log.Warnf("[%d/%d] | %s | [getTokenFaucet] | Attempt: [%d/%d] | Wrong Response Status Code: %v\n",
-					global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, respStatus,
-				)
-			}
-	
-			message, success := gjson.Get(json, "message").String(), gjson.Get(json, "success").Bool()
-			if message == "" && success {
-				msg := fmt.Sprintf("[%d/%d] | %s | [getTokenFaucet] | Attempt: [%d/%d] | Successfully get tokens from Faucet\n",
-					global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts,
-				)
-				log.Info(msg)
-				return true, nil
-	
-			} else if !success {
-				msg := fmt.Sprintf("[%d/%d] | %s | [getTokenFaucet] | Attempt: [%d/%d] | Failed to get tokens from Faucet: %v\n",
-					global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, message,
-				)
-				return false, errors.New(msg)
+		var cooldown *faucet.CooldownError
+		if errors.As(err, &cooldown) {
+			wait := cooldown.Wait
+			if wait == 0 {
+				wait = faucetCooldown()
 			}
+			recordCooldown(accountData.AccountAddress, wait)
 		}
 
-		select {
-		case <-ctx.Done():
-			log.Warnf("[%d/%d] | %s | [getTokenFaucet] | Attempt: [%d/%d] | Cancelled while waiting for retry\n",
-				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts,
-			)
-			return false, err
-		case <-time.After(retryDelay):
-			log.Infof("[%d/%d] | %s | [getTokenFaucet] | Attempt: [%d/%d] | Retry after %v seconds ...\n",
-				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, maxAttempts, retryDelay.Seconds(),
-			)
+		log.Warnf("[%d/%d] | %s | [getTokenFaucet] | Attempt: [%d/%d] | Failed to get tokens from Faucet: %v\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, i, faucetMaxAttempts, err,
+		)
+
+		if !faucet.Retryable(err) || !waitRetry(ctx, accountData, "getTokenFaucet", i) {
+			break
 		}
 	}
-	return false, err
+	return err
+}
+
+// claimFailure is the faucet_claims_total result of a claim getTokenFaucet gave up on
+func claimFailure(err error) string {
+	var cooldown *faucet.CooldownError
+	var captcha *faucet.CaptchaError
+	var rejected *faucet.RejectedError
+
+	switch {
+	case errors.As(err, &cooldown):
+		return "cooldown"
+	case errors.As(err, &captcha):
+		return "captcha"
+	case errors.As(err, &rejected):
+		return "rejected"
+	}
+	return "failed"
 }
 
 func FaucetTokens(ctx context.Context, accountData types.AccountData) (bool, error) {
+	if global.Network.Faucet.URL == "" {
+		msg := fmt.Sprintf("[%d/%d] | %s | [FaucetTokens] | Network %s has no faucet\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, global.NetworkName,
+		)
+		return false, errors.New(msg)
+	}
+
+	// skip before paying for a captcha the faucet would reject
+	if next := faucetNextClaim(accountData.AccountAddress); time.Now().Before(next) {
+		log.Warnf("[%d/%d] | %s | [FaucetTokens] | Faucet cooldown until %s, skip\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, next.Format(time.DateTime),
+		)
+		metrics.FaucetClaims.WithLabelValues("cooldown").Inc()
+		return true, types.ErrSkipped
+	}
+
 	if global.Config.CapmonsterAPIKey == "" {
 		msg := fmt.Sprintf("[%d/%d] | %s | [FaucetTokens] | You miss Capmonster API Key\n",
 			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress,
@@ -331,8 +219,20 @@ func FaucetTokens(ctx context.Context, accountData types.AccountData) (bool, err
 		return false, errors.New(msg)
 	}
 
+	// captcha and claim leave from the same IP for every run of this wallet
+	httpClient, err := utils.GetAccountClient(accountData)
+
+	if err != nil {
+		msg := fmt.Sprintf("[%d/%d] | %s | [FaucetTokens] | Problem with proxy: %v\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, err,
+		)
+		return false, errors.New(msg)
+	}
+
+	solver := capmonster.New(httpClient, global.Config.CapmonsterAPIKey, global.Config.CapmonsterURL)
+
 	// check balance
-	result := checkBalance(ctx, accountData)
+	result := checkBalance(ctx, solver, accountData)
 
 	if !result {
 		msg := fmt.Sprintf("[%d/%d] | %s | [FaucetTokens] | Not enough money for Capmonster captcha service\n",
@@ -342,7 +242,7 @@ func FaucetTokens(ctx context.Context, accountData types.AccountData) (bool, err
 	}
 
 	// create task for solving captcha
-	taskId, err := createTask(ctx, accountData)
+	taskID, err := createTask(ctx, solver, accountData)
 
 	if err != nil {
 		msg := fmt.Sprintf("[%d/%d] | %s | [FaucetTokens] | Problem with creating task for captcha solving\n",
@@ -352,7 +252,7 @@ func FaucetTokens(ctx context.Context, accountData types.AccountData) (bool, err
 	}
 
 	// try to resolve captcha
-	token, err := resultCaptcha(ctx, accountData, taskId)
+	token, err := resultCaptcha(ctx, solver, accountData, taskID)
 
 	if err != nil {
 		msg := fmt.Sprintf("[%d/%d] | %s | [FaucetTokens] | Problem with getting captcha token\n",
@@ -361,14 +261,84 @@ func FaucetTokens(ctx context.Context, accountData types.AccountData) (bool, err
 		return false, errors.New(msg)
 	}
 
-	_, err = getTokenFaucet(ctx, accountData, token)
+	run, err := internal.CurrentRun()
 
 	if err != nil {
-		msg := fmt.Sprintf("[%d/%d] | %s | [FaucetTokens] | Problem with getting test tokens from Faucet\n",
-			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress,
+		msg := fmt.Sprintf("[%d/%d] | %s | [FaucetTokens] | Problem with connecting to RPC: %v\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, err,
 		)
 		return false, errors.New(msg)
 	}
 
+	client := internal.NewClient(run, nil, &accountData)
+
+	// balance before the claim to tell the payout apart
+	balanceBefore, err := client.GetBalance()
+
+	if err != nil {
+		msg := fmt.Sprintf("[%d/%d] | %s | [FaucetTokens] | Problem with getting balance: %v\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, err,
+		)
+		return false, errors.New(msg)
+	}
+
+	err = getTokenFaucet(ctx, faucet.New(httpClient, faucetConfig()), accountData, token)
+
+	if err != nil {
+		metrics.FaucetClaims.WithLabelValues(claimFailure(err)).Inc()
+		msg := fmt.Sprintf("[%d/%d] | %s | [FaucetTokens] | Problem with getting test tokens from Faucet: %v\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, err,
+		)
+		return false, errors.New(msg)
+	}
+
+	return verifyPayout(ctx, client, balanceBefore)
+}
+
+// verifyPayout waits for the claimed tokens to arrive and records the outcome in the state file
+func verifyPayout(ctx context.Context, client *internal.Client, balanceBefore *big.Int) (bool, error) {
+	address := client.Account.AccountAddress
+	claimedAt := time.Now()
+
+	timeout := time.Duration(global.Config.Faucet.PayoutTimeout) * time.Second
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	balanceAfter, waitErr := client.WaitForBalanceIncrease(waitCtx, balanceBefore)
+
+	record := state.Faucet{Status: state.FaucetClaimedUnpaid, ClaimedAt: claimedAt}
+	if waitErr == nil {
+		record.Status = state.FaucetPaid
+		record.Amount = utils.FormatEther(new(big.Int).Sub(balanceAfter, balanceBefore))
+		record.PayoutSeconds = time.Since(claimedAt).Seconds()
+	}
+
+	if store, err := state.Default(global.Config.StatePath); err != nil {
+		log.Warnf("[%d/%d] | %s | [FaucetTokens] | Problem with opening state file: %v\n",
+			global.CurrentProgress, global.TargetProgress, address, err,
+		)
+	} else if err = store.Update(address, func(account *state.Account) { account.Faucet = record }); err != nil {
+		log.Warnf("[%d/%d] | %s | [FaucetTokens] | Problem with saving state file: %v\n",
+			global.CurrentProgress, global.TargetProgress, address, err,
+		)
+	}
+
+	if waitErr != nil {
+		metrics.FaucetClaims.WithLabelValues("claimed_unpaid").Inc()
+
+		msg := fmt.Sprintf("[%d/%d] | %s | [FaucetTokens] | Claim accepted but no payout within %v, marked %s\n",
+			global.CurrentProgress, global.TargetProgress, address, timeout, state.FaucetClaimedUnpaid,
+		)
+		log.Error(msg)
+		return false, errors.New(msg)
+	}
+
+	metrics.FaucetClaims.WithLabelValues("paid").Inc()
+
+	log.Infof("[%d/%d] | %s | [FaucetTokens] | Received %s ETH in %.0f seconds\n",
+		global.CurrentProgress, global.TargetProgress, address, record.Amount, record.PayoutSeconds,
+	)
+
 	return true, nil
 }
@@ -0,0 +1,45 @@
+package megaeth
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	log "github.com/sirupsen/logrus"
+
+	"main/pkg/global"
+	"main/pkg/state"
+)
+
+// faucetCooldown is the wait between claims of one address, 0 disables it
+func faucetCooldown() time.Duration {
+	return time.Duration(global.Config.Faucet.Cooldown) * time.Hour
+}
+
+// faucetNextClaim returns when address may claim again according to the state file
+func faucetNextClaim(address common.Address) time.Time {
+	store, err := state.Default(global.Config.StatePath)
+	if err != nil {
+		log.Warnf("[%d/%d] | %s | [FaucetTokens] | Problem with opening state file: %v\n",
+			global.CurrentProgress, global.TargetProgress, address, err,
+		)
+		return time.Time{}
+	}
+
+	return store.Get(address).Faucet.NextClaim(faucetCooldown())
+}
+
+// recordCooldown remembers that the faucet rejected address until now + wait
+func recordCooldown(address common.Address, wait time.Duration) {
+	store, err := state.Default(global.Config.StatePath)
+	if err == nil {
+		err = store.Update(address, func(account *state.Account) {
+			account.Faucet.CooldownUntil = time.Now().Add(wait)
+		})
+	}
+
+	if err != nil {
+		log.Warnf("[%d/%d] | %s | [FaucetTokens] | Problem with saving state file: %v\n",
+			global.CurrentProgress, global.TargetProgress, address, err,
+		)
+	}
+}
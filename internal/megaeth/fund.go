@@ -0,0 +1,362 @@
+package megaeth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	log "github.com/sirupsen/logrus"
+
+	"main/internal"
+	"main/pkg/global"
+	accTypes "main/pkg/types"
+	"main/pkg/utils"
+)
+
+const (
+	// disperseBatchSize is the number of recipients per disperseEther call
+	disperseBatchSize = 100
+	// fundReceiptTimeout limits how long Execute waits for sent transfers to be mined
+	fundReceiptTimeout = 3 * time.Minute
+)
+
+type FundTransfer struct {
+	Address common.Address
+	Balance *big.Int
+	Amount  *big.Int
+}
+
+// FundPlan is the list of transfers from the funding wallet, built before anything is sent
+type FundPlan struct {
+	Transfers []FundTransfer
+	Total     *big.Int
+	// GasCost is the fee cap of every transaction the plan sends, paid on top of Total
+	GasCost       *big.Int
+	FunderBalance *big.Int
+
+	funder   *internal.Client
+	disperse common.Address
+}
+
+// fundAmount decides how much a wallet with balance receives, nil when it is skipped
+func fundAmount(balance *big.Int, threshold *big.Int, target *big.Int, minAmount *big.Int, maxAmount *big.Int) *big.Int {
+	if threshold != nil && balance.Cmp(threshold) >= 0 {
+		return nil
+	}
+
+	if target != nil {
+		if balance.Cmp(target) >= 0 {
+			return nil
+		}
+		return new(big.Int).Sub(target, balance)
+	}
+
+	return utils.RandomBigInt(minAmount, maxAmount)
+}
+
+func parseOptionalEther(name string, amount string) (*big.Int, error) {
+	if amount == "" {
+		return nil, nil
+	}
+
+	value, err := utils.ParseEther(amount)
+	if err != nil {
+		return nil, fmt.Errorf("fund.%s: %w", name, err)
+	}
+	return value, nil
+}
+
+// PlanFunding reads balances of accounts and computes transfers from the funder configured in config.yaml
+func PlanFunding(ctx context.Context, accounts []accTypes.AccountData) (*FundPlan, error) {
+	settings := global.Config.Fund
+
+	if settings.FunderKey == "" {
+		return nil, errors.New("fund.funder_key is missing")
+	}
+
+	funderAccount, err := utils.ParseAccountKey(settings.FunderKey)
+	if err != nil {
+		return nil, fmt.Errorf("fund.funder_key: %w", err)
+	}
+
+	target, err := parseOptionalEther("target_balance", settings.TargetBalance)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold, err := parseOptionalEther("threshold", settings.Threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	minAmount, err := parseOptionalEther("amount.min", settings.Amount.Min)
+	if err != nil {
+		return nil, err
+	}
+
+	maxAmount, err := parseOptionalEther("amount.max", settings.Amount.Max)
+	if err != nil {
+		return nil, err
+	}
+
+	if target == nil {
+		if minAmount == nil {
+			return nil, errors.New("either fund.target_balance or fund.amount must be set")
+		}
+		if maxAmount == nil {
+			maxAmount = minAmount
+		}
+	}
+
+	if threshold == nil {
+		threshold = target
+	}
+
+	funder, ok := internal.GetClient(&funderAccount)
+	if !ok {
+		return nil, errors.New("problem with funder client initialization")
+	}
+
+	plan := &FundPlan{Total: big.NewInt(0), funder: funder}
+
+	if disperse := global.Network.Contracts.Disperse; disperse != "" {
+		if !common.IsHexAddress(disperse) {
+			return nil, fmt.Errorf("invalid contracts.disperse %q of network %s", disperse, global.NetworkName)
+		}
+		plan.disperse = common.HexToAddress(disperse)
+	}
+
+	if plan.FunderBalance, err = funder.Rpc.BalanceAt(ctx, funder.Account.AccountAddress, nil); err != nil {
+		return nil, fmt.Errorf("problem with getting funder balance: %w", err)
+	}
+
+	addresses := make([]common.Address, 0, len(accounts))
+	for _, account := range accounts {
+		if account.AccountAddress != funder.Account.AccountAddress {
+			addresses = append(addresses, account.AccountAddress)
+		}
+	}
+
+	for _, state := range funder.Run.LoadAccountStates(ctx, addresses) {
+		if state.Err != nil {
+			return nil, fmt.Errorf("problem with getting balance of %s: %w", state.Address, state.Err)
+		}
+		balance := state.Balance
+
+		amount := fundAmount(balance, threshold, target, minAmount, maxAmount)
+		if amount == nil || amount.Sign() == 0 {
+			continue
+		}
+
+		plan.Transfers = append(plan.Transfers, FundTransfer{
+			Address: state.Address,
+			Balance: balance,
+			Amount:  amount,
+		})
+		plan.Total.Add(plan.Total, amount)
+	}
+
+	if plan.GasCost, err = plan.estimateGasCost(ctx); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// estimateGasCost returns the gas of every transaction of the plan at the current fee cap.
+// Amounts do not change the gas of a transfer, so 1 wei stands in for them and the
+// estimate does not fail on a funder balance below the total.
+func (p *FundPlan) estimateGasCost(ctx context.Context) (*big.Int, error) {
+	gasCost := big.NewInt(0)
+	if len(p.Transfers) == 0 {
+		return gasCost, nil
+	}
+
+	fees, err := p.funder.Run.Fees(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("problem with getting fee data: %w", err)
+	}
+
+	from := p.funder.Account.AccountAddress
+
+	if p.disperse == (common.Address{}) {
+		gas, err := p.funder.Rpc.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &p.Transfers[0].Address, Value: big.NewInt(1)})
+		if err != nil {
+			return nil, fmt.Errorf("problem with estimating transfer gas: %w", err)
+		}
+
+		gasCost.Mul(fees.MaxFee(), new(big.Int).SetUint64(gas*uint64(len(p.Transfers))))
+		return gasCost, nil
+	}
+
+	disperseABI, err := utils.LoadABI(filepath.Join("abi", "disperse.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	for start := 0; start < len(p.Transfers); start += disperseBatchSize {
+		end := min(start+disperseBatchSize, len(p.Transfers))
+
+		recipients := make([]common.Address, 0, end-start)
+		values := make([]*big.Int, 0, end-start)
+		for _, transfer := range p.Transfers[start:end] {
+			recipients = append(recipients, transfer.Address)
+			values = append(values, big.NewInt(1))
+		}
+
+		data, err := disperseABI.Pack("disperseEther", recipients, values)
+		if err != nil {
+			return nil, fmt.Errorf("problem with encoding disperseEther: %w", err)
+		}
+
+		gas, err := p.funder.Rpc.EstimateGas(ctx, ethereum.CallMsg{
+			From:  from,
+			To:    &p.disperse,
+			Value: big.NewInt(int64(end - start)),
+			Data:  data,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("problem with estimating disperse gas of wallets %d-%d: %w", start+1, end, err)
+		}
+
+		gasCost.Add(gasCost, new(big.Int).Mul(fees.MaxFee(), new(big.Int).SetUint64(gas)))
+	}
+
+	return gasCost, nil
+}
+
+// required is the funder balance the plan needs: transfers plus gas
+func (p *FundPlan) required() *big.Int {
+	return new(big.Int).Add(p.Total, p.GasCost)
+}
+
+// Print writes the preview table of the plan
+func (p *FundPlan) Print(w io.Writer) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(table, "#\tAddress\tBalance (ETH)\tAmount (ETH)")
+	for i, transfer := range p.Transfers {
+		fmt.Fprintf(table, "%d\t%s\t%s\t%s\n",
+			i+1, transfer.Address, utils.FormatEther(transfer.Balance), utils.FormatEther(transfer.Amount),
+		)
+	}
+	table.Flush()
+
+	mode := "sequential transfers"
+	if p.disperse != (common.Address{}) {
+		mode = "disperse contract " + p.disperse.Hex()
+	}
+
+	fmt.Fprintf(w, "\nFunder: %s | Balance: %s ETH\n", p.funder.Account.AccountAddress, utils.FormatEther(p.FunderBalance))
+	fmt.Fprintf(w, "Wallets to fund: %d | Total: %s ETH | Gas: up to %s ETH | Required: %s ETH | Mode: %s\n",
+		len(p.Transfers), utils.FormatEther(p.Total), utils.FormatEther(p.GasCost), utils.FormatEther(p.required()), mode,
+	)
+}
+
+// Execute sends the planned transfers with consecutive nonces and waits for them to be mined
+func (p *FundPlan) Execute(ctx context.Context) error {
+	if len(p.Transfers) == 0 {
+		return nil
+	}
+
+	if p.required().Cmp(p.FunderBalance) > 0 {
+		return fmt.Errorf("funder balance %s ETH is less than total %s ETH plus gas %s ETH",
+			utils.FormatEther(p.FunderBalance), utils.FormatEther(p.Total), utils.FormatEther(p.GasCost),
+		)
+	}
+
+	nonce, err := p.funder.GetNonce()
+	if err != nil {
+		return fmt.Errorf("problem with getting funder nonce: %w", err)
+	}
+
+	var hashes []common.Hash
+	if p.disperse != (common.Address{}) {
+		hashes, err = p.sendDisperse(ctx, nonce)
+	} else {
+		hashes, err = p.sendSequential(ctx, nonce)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, fundReceiptTimeout)
+	defer cancel()
+
+	for _, hash := range hashes {
+		if _, waitErr := p.funder.WaitForReceipt(waitCtx, hash); waitErr != nil {
+			log.Errorf("[Fund] | %s | %v\n", hash, waitErr)
+			continue
+		}
+		log.Infof("[Fund] | %s | Mined\n", hash)
+	}
+
+	return err
+}
+
+func (p *FundPlan) sendSequential(ctx context.Context, nonce uint64) ([]common.Hash, error) {
+	var hashes []common.Hash
+
+	for i, transfer := range p.Transfers {
+		tx, err := sendTransactionWithNonce(ctx, p.funder, "Fund", transfer.Address.Hex(), nil, transfer.Amount, nonce)
+		if err != nil {
+			// later transfers would be stuck behind the missing nonce
+			return hashes, fmt.Errorf("stopped at transfer %d/%d: %w", i+1, len(p.Transfers), err)
+		}
+
+		log.Infof("[%d/%d] | %s | [Fund] | Sent %s ETH | Tx: %s\n",
+			i+1, len(p.Transfers), transfer.Address, utils.FormatEther(transfer.Amount), tx.Hash(),
+		)
+
+		hashes = append(hashes, tx.Hash())
+		nonce++
+	}
+
+	return hashes, nil
+}
+
+func (p *FundPlan) sendDisperse(ctx context.Context, nonce uint64) ([]common.Hash, error) {
+	disperseABI, err := utils.LoadABI(filepath.Join("abi", "disperse.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var hashes []common.Hash
+
+	for start := 0; start < len(p.Transfers); start += disperseBatchSize {
+		end := min(start+disperseBatchSize, len(p.Transfers))
+
+		recipients := make([]common.Address, 0, end-start)
+		values := make([]*big.Int, 0, end-start)
+		total := big.NewInt(0)
+
+		for _, transfer := range p.Transfers[start:end] {
+			recipients = append(recipients, transfer.Address)
+			values = append(values, transfer.Amount)
+			total.Add(total, transfer.Amount)
+		}
+
+		data, err := disperseABI.Pack("disperseEther", recipients, values)
+		if err != nil {
+			return hashes, fmt.Errorf("problem with encoding disperseEther: %w", err)
+		}
+
+		tx, err := sendTransactionWithNonce(ctx, p.funder, "Fund", p.disperse.Hex(), data, total, nonce)
+		if err != nil {
+			return hashes, fmt.Errorf("stopped at batch %d-%d: %w", start+1, end, err)
+		}
+
+		log.Infof("[Fund] | Dispersed %s ETH to wallets %d-%d | Tx: %s\n",
+			utils.FormatEther(total), start+1, end, tx.Hash(),
+		)
+
+		hashes = append(hashes, tx.Hash())
+		nonce++
+	}
+
+	return hashes, nil
+}
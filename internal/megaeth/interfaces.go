@@ -6,30 +6,28 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/common"
+
 	accTypes "main/pkg/types"
 	"main/pkg/global"
 )
 
+// NativeCurrency is the sentinel address claim contracts use for payments in ETH
+var NativeCurrency = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+
+// NFT describes a drop minted through the claim function
 type NFT struct {
-	Value *big.Int
+	Value *big.Int // price per token
+	Quantity accTypes.Range
+	Currency common.Address
+	Signature []byte
+	ExactApprove bool
+	Allowlist string
 	ContractAddress string
 	DisplayName string
 }
 
-type AngryMonkeysNFT struct { NFT }
-
-type BlackholeNFT struct { NFT }
-
-type BloomNFT struct { NFT }
-
-type LapinNFT struct { NFT }
-
-type MegaCatNFT struct { NFT }
-
-type MegamafiaNFT struct { NFT }
-
-type XyrophNFT struct { NFT }
-
+// FunNFT describes a drop minted by sending raw calldata
 type FunNFT struct {
 	NFT
 	Data string
@@ -39,75 +37,73 @@ type Mint interface {
 	Mint(context.Context, accTypes.AccountData) (bool, error)
 }
 
+// GetDrop finds drop settings by module name in config.yaml
+func GetDrop(name string) (accTypes.DropConfig, bool) {
+	for _, drop := range global.Network.Drops {
+		if drop.Name == name {
+			return drop, true
+		}
+	}
+	return accTypes.DropConfig{}, false
+}
+
+// NewNFT converts drop settings into claim parameters
+func NewNFT(drop accTypes.DropConfig) (NFT, error) {
+	price, ok := new(big.Int).SetString(drop.Price, 10)
+	if drop.Price == "" {
+		price, ok = big.NewInt(0), true
+	}
+	if !ok {
+		return NFT{}, fmt.Errorf("%s: invalid price %q", drop.Name, drop.Price)
+	}
+
+	if !common.IsHexAddress(drop.Contract) {
+		return NFT{}, fmt.Errorf("%s: invalid contract address %q", drop.Name, drop.Contract)
+	}
+
+	currency := NativeCurrency
+	if drop.Currency != "" {
+		if !common.IsHexAddress(drop.Currency) {
+			return NFT{}, fmt.Errorf("%s: invalid currency address %q", drop.Name, drop.Currency)
+		}
+		currency = common.HexToAddress(drop.Currency)
+	}
+
+	quantity := drop.Quantity
+	if quantity.IsZero() {
+		quantity = accTypes.Range{Min: 1, Max: 1}
+	}
+	if quantity.Min <= 0 {
+		return NFT{}, fmt.Errorf("%s: quantity must be positive", drop.Name)
+	}
+
+	return NFT{
+		Value: price,
+		Quantity: quantity,
+		Currency: currency,
+		Signature: common.FromHex(drop.Signature),
+		ExactApprove: drop.ExactApprove,
+		Allowlist: drop.Allowlist,
+		ContractAddress: drop.Contract,
+		DisplayName: drop.Name,
+	}, nil
+}
+
 func GetInterfaceByModuleName() (Mint, error) {
-	switch global.Module {
-	case "Mint Megamafia NFT":
-		return MegamafiaNFT{
-			NFT: NFT{
-				Value: big.NewInt(0),
-				ContractAddress: "0xa3C89fEb775940886001E8f541f4b803AaD0a47B",
-				DisplayName: "Mint Megamafia NFT",
-			},
-		}, nil
-	case "Mint Mega Cat NFT":
-		return MegaCatNFT{
-			NFT: NFT{
-				Value: big.NewInt(0),
-				ContractAddress: "0x0837ec39d40CCdcea4b4B6bfCfb3d71E7EbFC71C",
-				DisplayName: "Mint Mega Cat NFT",
-			},
-		}, nil
-	case "Mint Blackhole NFT":
-		return BlackholeNFT{
-			NFT: NFT{
-				Value: big.NewInt(0),
-				ContractAddress: "0xcfD3dDe3A4B393a2a204ff16B112C2cA9B85abb7",
-				DisplayName: "Mint Blackhole NFT",
-			},
-		}, nil
-	case "Mint Xyroph NFT":
-		return XyrophNFT{
-			NFT: NFT{
-				Value: big.NewInt(1550000000000000),
-				ContractAddress: "0xd59522848e5429986d6fe6607aef6b8e7706aea5",
-				DisplayName: "Mint Xyroph NFT",
-			},
-		}, nil
-	case "Mint Lord Lapin NFT":
-		return LapinNFT{
-			NFT: NFT{
-				Value: big.NewInt(0),
-				ContractAddress: "0x0d7BEa5686E3c85cb018faa066AB36CF00b63eBB",
-				DisplayName: "Mint Lord Lapin NFT",
-			},
-		}, nil
-	case "Mint Angry Monkeys":
-		return AngryMonkeysNFT{
-			NFT: NFT{
-				Value: big.NewInt(1440000000000000),
-				ContractAddress: "0x8ac06714c0d417569bcc642cd74e48a64fe99504",
-				DisplayName: "Mint Angry Monkeys",
-			},
-		}, nil
-	case "Mint Bloom NFT":
-		return BloomNFT{
-			NFT: NFT{
-				Value: big.NewInt(0),
-				ContractAddress: "0xb33C085f82B253B12a9d36F8E8EdD123FFB53d31",
-				DisplayName: "Mint Bloom NFT",
-			},
-		}, nil
-	case "Mint FUN Starts NFT":
-		return FunNFT{
-			NFT: NFT{
-				Value: big.NewInt(0),
-				ContractAddress: "0xb8027dca96746f073896c45f65b720f9bd2afee7",
-				DisplayName: "Mint Fun NFT",
-			},
-			Data: "0x1249c58b",
-		}, nil
-	default:
-		msg := fmt.Sprintf("Struct with %s module name is not defined!", global.Module)
+	drop, ok := GetDrop(global.Module)
+	if !ok {
+		msg := fmt.Sprintf("Drop with %s module name is not defined!", global.Module)
 		return nil, errors.New(msg)
 	}
+
+	nft, err := NewNFT(drop)
+	if err != nil {
+		return nil, err
+	}
+
+	if drop.Data != "" {
+		return FunNFT{NFT: nft, Data: drop.Data}, nil
+	}
+
+	return nft, nil
 }
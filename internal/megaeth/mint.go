@@ -9,7 +9,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/common/math"
 
 	accTypes "main/pkg/types"
 	"main/internal"
@@ -26,52 +26,164 @@ func StartMint(m Mint) func(context.Context, accTypes.AccountData) (bool, error)
 	}
 }
 
-func (n NFT) Mint(ctx context.Context, accountData accTypes.AccountData) (bool, error) {
-	log.Infof("[%d/%d] | %s | [%s] | Start minting ...\n",
-		global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, n.DisplayName,
-	)
+// claimTerms are the parameters a single claim is sent with
+type claimTerms struct {
+	Quantity      *big.Int
+	PricePerToken *big.Int
+	Currency      common.Address
+	Claim         accTypes.ClaimStruct
+}
 
-	var contractAddress = n.ContractAddress
+// publicClaim is the allowlist proof sent in public phases: no proof, default limit and price
+func publicClaim() accTypes.ClaimStruct {
+	return accTypes.ClaimStruct{
+		Proof:       []common.Hash{},
+		LeafIndex:   big.NewInt(0),
+		LeafAmount:  new(big.Int).Set(math.MaxBig256),
+		LeafAddress: common.Address{},
+	}
+}
 
-	quantity := big.NewInt(1)
-	currency := common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
-	pricePerToken := n.Value
+// applyAllowlist fills the claim proof from the drop's allowlist and returns the
+// per wallet limit it grants, nil when the account is not allowlisted
+func (n NFT) applyAllowlist(client *internal.Client, terms *claimTerms, activeClaim *ActiveClaim) (*big.Int, error) {
+	allowlist, err := utils.LoadAllowlist(n.Allowlist)
+	if err != nil {
+		return nil, fmt.Errorf("problem with loading allowlist: %w", err)
+	}
 
-	leafIndex := big.NewInt(0)
-	leafAmount := new(big.Int)
-	leafAmount.SetString("115792089237316195423570985008687907853269984665640564039457584007913129639935", 10)
-	leafAddress := common.HexToAddress("0x0000000000000000000000000000000000000000")
+	entry, proof, ok := allowlist.Proof(client.Account.AccountAddress)
+	if !ok {
+		log.Infof("[%d/%d] | %s | [%s] | Account is not in allowlist, claiming as public\n",
+			global.CurrentProgress, global.TargetProgress, client.Account.AccountAddress, n.DisplayName,
+		)
+		return nil, nil
+	}
 
-	claim := accTypes.ClaimStruct{
-		Proof:       []common.Hash{},
-		LeafIndex:   leafIndex,
-		LeafAmount:  leafAmount,
-		LeafAddress: leafAddress,
+	if activeClaim != nil && activeClaim.Condition.MerkleRoot != allowlist.Tree.Root() {
+		log.Warnf("[%d/%d] | %s | [%s] | Allowlist root %s differs from claim condition root %s\n",
+			global.CurrentProgress, global.TargetProgress, client.Account.AccountAddress, n.DisplayName,
+			allowlist.Tree.Root(), common.Hash(activeClaim.Condition.MerkleRoot),
+		)
 	}
-	signature := []byte{}
 
-	contractABI, err := utils.LoadABI(filepath.Join("abi", "claim.json"))
-	if err != nil {
-		return false, err
+	terms.Claim = accTypes.ClaimStruct{
+		Proof:       proof,
+		LeafIndex:   entry.MaxClaimable,
+		LeafAmount:  entry.Price,
+		LeafAddress: entry.Currency,
 	}
 
-	data, err := contractABI.Pack(
-		"claim",
-		accountData.AccountAddress,
-		quantity,
-		currency,
-		pricePerToken,
-		claim,
-		signature,
-	)
+	// thirdweb uses the proof price only when it is set, and the proof currency
+	// only together with a set price; otherwise the claim condition's apply
+	if entry.Price.Cmp(math.MaxBig256) != 0 {
+		terms.PricePerToken = entry.Price
+
+		if entry.Currency != (common.Address{}) {
+			terms.Currency = entry.Currency
+		}
+	}
+
+	return entry.MaxClaimable, nil
+}
+
+// claimTerms picks the quantity to claim, the price per token, the payment currency
+// and the allowlist proof, respecting the active claim condition when the drop exposes one.
+// Configured price and currency cap what is paid: a condition asking for more or for
+// another currency fails the claim, so a drop changed mid-run cannot drain wallets.
+func (n NFT) claimTerms(ctx context.Context, client *internal.Client) (*claimTerms, error) {
+	terms := &claimTerms{
+		Quantity:      big.NewInt(n.Quantity.Random()),
+		PricePerToken: n.Value,
+		Currency:      n.Currency,
+		Claim:         publicClaim(),
+	}
+
+	activeClaim, err := getActiveClaim(ctx, client, common.HexToAddress(n.ContractAddress))
 	if err != nil {
-		msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Problem with encoding parameters\n",
-			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, n.DisplayName,
+		log.Warnf("[%d/%d] | %s | [%s] | Claim condition is not readable, using configured quantity %v: %v\n",
+			global.CurrentProgress, global.TargetProgress, client.Account.AccountAddress, n.DisplayName, terms.Quantity, err,
+		)
+		activeClaim = nil
+	} else {
+		if activeClaim.Condition.Currency != terms.Currency {
+			return nil, fmt.Errorf("claim condition currency %s differs from configured %s",
+				activeClaim.Condition.Currency, terms.Currency,
+			)
+		}
+
+		switch activeClaim.Condition.PricePerToken.Cmp(terms.PricePerToken) {
+		case 1:
+			return nil, fmt.Errorf("claim condition price %v is above configured price %v",
+				activeClaim.Condition.PricePerToken, terms.PricePerToken,
+			)
+		case -1:
+			log.Warnf("[%d/%d] | %s | [%s] | Configured price %v is above claim condition, using %v\n",
+				global.CurrentProgress, global.TargetProgress, client.Account.AccountAddress, n.DisplayName,
+				terms.PricePerToken, activeClaim.Condition.PricePerToken,
+			)
+			terms.PricePerToken = activeClaim.Condition.PricePerToken
+		}
+	}
+
+	var allowlistLimit *big.Int
+	if n.Allowlist != "" {
+		if allowlistLimit, err = n.applyAllowlist(client, terms, activeClaim); err != nil {
+			return nil, err
+		}
+	}
+
+	if activeClaim == nil {
+		return terms, nil
+	}
+
+	limit := activeClaim.Condition.QuantityLimitPerWallet
+	if allowlistLimit != nil && allowlistLimit.Sign() > 0 {
+		limit = allowlistLimit
+	}
+
+	allowed := activeClaim.RemainingForWallet(limit)
+	if remaining := activeClaim.RemainingSupply(); remaining != nil && remaining.Cmp(allowed) < 0 {
+		allowed = remaining
+	}
+
+	if allowed.Sign() == 0 {
+		return nil, fmt.Errorf("nothing left to claim (claimed by wallet: %v, limit per wallet: %v, supply: %v/%v)",
+			activeClaim.ClaimedByWallet, limit,
+			activeClaim.Condition.SupplyClaimed, activeClaim.Condition.MaxClaimableSupply,
 		)
-		log.Error(msg)
-		return false, errors.New(msg)
 	}
 
+	if terms.Quantity.Cmp(allowed) > 0 {
+		log.Infof("[%d/%d] | %s | [%s] | Quantity lowered from %v to %v by claim condition\n",
+			global.CurrentProgress, global.TargetProgress, client.Account.AccountAddress, n.DisplayName, terms.Quantity, allowed,
+		)
+		terms.Quantity = allowed
+	}
+
+	return terms, nil
+}
+
+// TotalPrice is the amount paid for the claim in its currency
+func (t *claimTerms) TotalPrice() *big.Int {
+	return new(big.Int).Mul(t.PricePerToken, t.Quantity)
+}
+
+// Value is the amount of ETH sent with the claim transaction
+func (t *claimTerms) Value() *big.Int {
+	if t.Currency != NativeCurrency {
+		return big.NewInt(0)
+	}
+	return t.TotalPrice()
+}
+
+func (n NFT) Mint(ctx context.Context, accountData accTypes.AccountData) (bool, error) {
+	log.Infof("[%d/%d] | %s | [%s] | Start minting ...\n",
+		global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, n.DisplayName,
+	)
+
+	var contractAddress = n.ContractAddress
+
 	client, ok := internal.GetClient(&accountData)
 	if !ok {
 		msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Problem with client initialization\n",
@@ -81,55 +193,68 @@ func (n NFT) Mint(ctx context.Context, accountData accTypes.AccountData) (bool,
 		return false, errors.New(msg)
 	}
 
-	tx, err := client.BuildTransaction(
-		contractAddress,
-		data,
-		n.Value,
-	)
-
+	terms, err := n.claimTerms(ctx, client)
 	if err != nil {
-		msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Problem with building transaction: %v\n",
+		msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Skip minting: %v\n",
 			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, n.DisplayName, err,
 		)
-		log.Error(msg)
+		log.Warn(msg)
 		return false, errors.New(msg)
 	}
 
-	chainID, err := client.GetChainID()
-	if err != nil {
-		msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Problem with getting chainID: %v\n",
-			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, n.DisplayName, err,
+	op := &operation{}
+
+	if terms.Currency != NativeCurrency && terms.TotalPrice().Sign() > 0 {
+		err = ensureTokenPayment(
+			ctx, client, op, n.DisplayName,
+			terms.Currency, common.HexToAddress(contractAddress), terms.TotalPrice(), n.ExactApprove,
 		)
-		log.Error(msg)
-		return false, errors.New(msg)
+		if err != nil {
+			msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Problem with ERC-20 payment: %v | Operation: %s\n",
+				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, n.DisplayName, err, op,
+			)
+			log.Error(msg)
+			return false, errors.New(msg)
+		}
+	}
+
+	signature := n.Signature
+	if signature == nil {
+		signature = []byte{}
+	}
+
+	contractABI, err := utils.LoadABI(filepath.Join("abi", "claim.json"))
+	if err != nil {
+		return false, err
 	}
 
-	signedTx, err := types.SignTx(
-		tx,
-		types.NewLondonSigner(chainID),
-		client.Account.AccountKey,
+	data, err := contractABI.Pack(
+		"claim",
+		accountData.AccountAddress,
+		terms.Quantity,
+		terms.Currency,
+		terms.PricePerToken,
+		terms.Claim,
+		signature,
 	)
 	if err != nil {
-		msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Problem with signing tx: %v\n",
-			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, n.DisplayName, err,
+		msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Problem with encoding parameters\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, n.DisplayName,
 		)
 		log.Error(msg)
 		return false, errors.New(msg)
 	}
 
-	err = client.Rpc.SendTransaction(ctx, signedTx)
+	tx, err := sendTransaction(ctx, client, n.DisplayName, contractAddress, data, terms.Value())
 	if err != nil {
-		msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Problem with signing tx: %v\n",
-			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, n.DisplayName, err,
-		)
-		log.Error(err)
-		return false, errors.New(msg)
-	} else {
-		msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Successfully executed transaction\n",
-			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, n.DisplayName,
-		)
-		log.Info(msg)
+		return false, err
 	}
+	op.add("claim", tx.Hash())
+
+	msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Successfully executed transaction (quantity: %v) | Operation: %s\n",
+		global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, n.DisplayName, terms.Quantity, op,
+	)
+	log.Info(msg)
 
 	return true, nil
 }
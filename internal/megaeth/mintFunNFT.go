@@ -7,7 +7,6 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
 
 	accTypes "main/pkg/types"
 	"main/internal"
@@ -44,20 +43,7 @@ func (f FunNFT) MintFunNFT(ctx context.Context, accountData accTypes.AccountData
 		return false, errors.New(msg)
 	}
 
-	chainID, err := client.GetChainID()
-	if err != nil {
-		msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Problem with getting chainID: %v\n",
-			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, f.DisplayName, err,
-		)
-		log.Error(msg)
-		return false, errors.New(msg)
-	}
-
-	signedTx, err := types.SignTx(
-		tx,
-		types.NewLondonSigner(chainID),
-		client.Account.AccountKey,
-	)
+	signedTx, err := client.SignTx(ctx, tx)
 	if err != nil {
 		msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Problem with signing tx: %v\n",
 			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, f.DisplayName, err,
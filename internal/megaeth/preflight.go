@@ -0,0 +1,127 @@
+package megaeth
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	log "github.com/sirupsen/logrus"
+
+	"main/internal"
+	accTypes "main/pkg/types"
+	"main/pkg/utils"
+)
+
+// Preflight drops accounts that cannot mint from the minter's active claim
+// condition before any transaction is sent. Raw calldata drops are not checked.
+func Preflight(ctx context.Context, minter Mint, accounts []accTypes.AccountData) []accTypes.AccountData {
+	nft, ok := minter.(NFT)
+	if !ok || len(accounts) == 0 {
+		return accounts
+	}
+
+	eligible, err := nft.preflight(ctx, accounts)
+	if err != nil {
+		log.Warnf("[%s] | [Preflight] | Skipped, claim condition is not readable: %v\n", nft.DisplayName, err)
+		return accounts
+	}
+
+	log.Infof("[%s] | [Preflight] | %d of %d accounts can mint\n", nft.DisplayName, len(eligible), len(accounts))
+
+	return eligible
+}
+
+func (n NFT) preflight(ctx context.Context, accounts []accTypes.AccountData) ([]accTypes.AccountData, error) {
+	run, err := internal.CurrentRun()
+	if err != nil {
+		return nil, err
+	}
+
+	multicall, err := run.Multicall()
+	if err != nil {
+		return nil, err
+	}
+
+	claimABI, err := utils.LoadABI(filepath.Join("abi", "claim.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	erc721ABI, err := utils.LoadABI(filepath.Join("abi", "erc721.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	contract := common.HexToAddress(n.ContractAddress)
+	client := internal.NewClient(run, nil, &accounts[0])
+
+	// the condition is shared by every account, so it is read once
+	activeClaim, err := getActiveClaim(ctx, client, contract)
+	if err != nil {
+		return nil, err
+	}
+	condition := activeClaim.Condition
+
+	if out, err := callView(ctx, client, erc721ABI, contract, "totalSupply"); err == nil {
+		log.Infof("[%s] | [Preflight] | Total supply: %v | Phase: %v/%v claimed\n",
+			n.DisplayName, out[0], condition.SupplyClaimed, condition.MaxClaimableSupply,
+		)
+	}
+
+	if remaining := activeClaim.RemainingSupply(); remaining != nil && remaining.Sign() == 0 {
+		log.Warnf("[%s] | [Preflight] | Claim phase is sold out\n", n.DisplayName)
+		return nil, nil
+	}
+
+	calls := make([]internal.Call3, 0, 2*len(accounts))
+	for _, account := range accounts {
+		call, err := internal.NewCall(claimABI, contract, "getSupplyClaimedByWallet", activeClaim.ID, account.AccountAddress)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, call, multicall.EthBalanceCall(account.AccountAddress))
+	}
+
+	results, err := multicall.Aggregate3(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	// the cheapest mint the account may attempt
+	minCost := big.NewInt(0)
+	if condition.Currency == NativeCurrency {
+		minCost.Mul(condition.PricePerToken, big.NewInt(max(n.Quantity.Min, 1)))
+	}
+
+	eligible := make([]accTypes.AccountData, 0, len(accounts))
+	for i, account := range accounts {
+		claimed, balance := results[2*i], results[2*i+1]
+
+		// allowlist entries override the wallet limit, which is checked per account at mint time
+		if claimed.Success && n.Allowlist == "" {
+			out, err := claimABI.Unpack("getSupplyClaimedByWallet", claimed.ReturnData)
+			if err == nil {
+				usage := ActiveClaim{Condition: condition, ClaimedByWallet: abi.ConvertType(out[0], new(big.Int)).(*big.Int)}
+				if usage.RemainingForWallet(condition.QuantityLimitPerWallet).Sign() == 0 {
+					log.Warnf("[%s] | [Preflight] | %s | Wallet limit %v reached, skip\n",
+						n.DisplayName, account.AccountAddress, condition.QuantityLimitPerWallet,
+					)
+					continue
+				}
+			}
+		}
+
+		if value := multicall.DecodeEthBalance(balance); value != nil && value.Cmp(minCost) < 0 {
+			log.Warnf("[%s] | [Preflight] | %s | Balance %s ETH does not cover price %s ETH, skip\n",
+				n.DisplayName, account.AccountAddress, utils.FormatEther(value), utils.FormatEther(minCost),
+			)
+			continue
+		}
+
+		eligible = append(eligible, account)
+	}
+
+	return eligible, nil
+}
@@ -0,0 +1,205 @@
+package megaeth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	log "github.com/sirupsen/logrus"
+
+	"main/internal"
+	"main/pkg/global"
+	accTypes "main/pkg/types"
+	"main/pkg/utils"
+)
+
+// sweepReceiptTimeout limits how long a sweep transfer is awaited
+const sweepReceiptTimeout = 3 * time.Minute
+
+type SweepResult struct {
+	Address common.Address
+	Balance *big.Int
+	Amount  *big.Int
+	GasCost *big.Int
+	TxHash  common.Hash
+	Status  string
+}
+
+var (
+	sweepResults []SweepResult
+	sweepMutex   sync.Mutex
+)
+
+func addSweepResult(result SweepResult) {
+	sweepMutex.Lock()
+	defer sweepMutex.Unlock()
+
+	sweepResults = append(sweepResults, result)
+}
+
+// PrintSweepReport writes the amounts moved from every processed account
+func PrintSweepReport(w io.Writer) {
+	sweepMutex.Lock()
+	defer sweepMutex.Unlock()
+
+	total := big.NewInt(0)
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(table, "Address\tBalance (ETH)\tSent (ETH)\tGas (ETH)\tStatus\tTx")
+	for _, result := range sweepResults {
+		txHash := ""
+		if result.TxHash != (common.Hash{}) {
+			txHash = result.TxHash.Hex()
+		}
+		if result.Status == "mined" {
+			total.Add(total, result.Amount)
+		}
+
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			result.Address, utils.FormatEther(result.Balance), utils.FormatEther(result.Amount),
+			utils.FormatEther(result.GasCost), result.Status, txHash,
+		)
+	}
+	table.Flush()
+
+	fmt.Fprintf(w, "\nSwept %s ETH to %s\n", utils.FormatEther(total), global.Config.Sweep.To)
+}
+
+// Sweep sends the whole balance of the account minus the gas cost of the transfer to sweep.to.
+// The fee cap is reserved, so the difference to the gas price actually paid stays as dust.
+func Sweep(ctx context.Context, accountData accTypes.AccountData) (bool, error) {
+	result := SweepResult{Address: accountData.AccountAddress, Status: "failed"}
+	defer func() { addSweepResult(result) }()
+
+	fail := func(format string, args ...interface{}) (bool, error) {
+		msg := fmt.Sprintf("[%d/%d] | %s | [Sweep] | "+format+"\n",
+			append([]interface{}{global.CurrentProgress, global.TargetProgress, accountData.AccountAddress}, args...)...,
+		)
+		log.Error(msg)
+		return false, errors.New(msg)
+	}
+
+	if !common.IsHexAddress(global.Config.Sweep.To) {
+		return fail("Invalid sweep.to address %q", global.Config.Sweep.To)
+	}
+	to := common.HexToAddress(global.Config.Sweep.To)
+
+	if to == accountData.AccountAddress {
+		result.Status = "destination"
+		return true, nil
+	}
+
+	minBalance := big.NewInt(0)
+	if global.Config.Sweep.MinBalance != "" {
+		var err error
+		if minBalance, err = utils.ParseEther(global.Config.Sweep.MinBalance); err != nil {
+			return fail("Invalid sweep.min_balance: %v", err)
+		}
+	}
+
+	client, ok := internal.GetClient(&accountData)
+	if !ok {
+		return fail("Problem with client initialization")
+	}
+
+	balance, err := client.Rpc.BalanceAt(ctx, accountData.AccountAddress, nil)
+	if err != nil {
+		return fail("Problem with getting balance: %v", err)
+	}
+	result.Balance = balance
+
+	if balance.Sign() == 0 || balance.Cmp(minBalance) < 0 {
+		result.Status = "below min balance"
+		log.Infof("[%d/%d] | %s | [Sweep] | Balance %s ETH is below minimum, skip\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, utils.FormatEther(balance),
+		)
+		return true, nil
+	}
+
+	gasLimit, err := client.Rpc.EstimateGas(ctx, ethereum.CallMsg{
+		From:  accountData.AccountAddress,
+		To:    &to,
+		Value: big.NewInt(1),
+	})
+	if err != nil {
+		return fail("Problem with estimating gas: %v", err)
+	}
+
+	fees, err := client.Run.Fees(ctx)
+	if err != nil {
+		return fail("Problem with getting fee data: %v", err)
+	}
+
+	maxFee := fees.MaxFee()
+	maxGasCost := new(big.Int).Mul(maxFee, new(big.Int).SetUint64(gasLimit))
+	amount := new(big.Int).Sub(balance, maxGasCost)
+
+	if amount.Sign() <= 0 {
+		result.Status = "balance below gas cost"
+		log.Infof("[%d/%d] | %s | [Sweep] | Balance %s ETH does not cover gas %s ETH, skip\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress,
+			utils.FormatEther(balance), utils.FormatEther(maxGasCost),
+		)
+		return true, nil
+	}
+
+	nonce, err := client.GetNonce()
+	if err != nil {
+		return fail("Problem with getting nonce: %v", err)
+	}
+
+	chainID, err := client.GetChainID()
+	if err != nil {
+		return fail("Problem with getting chainID: %v", err)
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasFeeCap: maxFee,
+		GasTipCap: fees.TipCap,
+		Gas:       gasLimit,
+		To:        &to,
+		Value:     amount,
+	})
+
+	signedTx, err := signAndSend(ctx, client, "Sweep", tx)
+	if err != nil {
+		return false, err
+	}
+
+	result.Amount = amount
+	result.TxHash = signedTx.Hash()
+	result.Status = "not mined"
+
+	waitCtx, cancel := context.WithTimeout(ctx, sweepReceiptTimeout)
+	defer cancel()
+
+	receipt, err := client.WaitForTransaction(waitCtx, signedTx)
+	if receipt != nil {
+		result.GasCost = new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	}
+	if err != nil {
+		if receipt != nil {
+			result.Status = "reverted"
+		}
+		return fail("Transfer of %s ETH to %s failed: %v", utils.FormatEther(amount), to, err)
+	}
+
+	result.Status = "mined"
+
+	log.Infof("[%d/%d] | %s | [Sweep] | Sent %s ETH to %s, gas %s ETH | Tx: %s\n",
+		global.CurrentProgress, global.TargetProgress, accountData.AccountAddress,
+		utils.FormatEther(amount), to, utils.FormatEther(result.GasCost), utils.ExplorerTx(signedTx.Hash().Hex()),
+	)
+
+	return true, nil
+}
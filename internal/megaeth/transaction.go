@@ -0,0 +1,106 @@
+package megaeth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	log "github.com/sirupsen/logrus"
+
+	"main/internal"
+	"main/pkg/global"
+	"main/pkg/utils"
+)
+
+// operation groups the transactions an account sends for one logical action,
+// e.g. an ERC-20 approval followed by the claim it pays for
+type operation struct {
+	steps []string
+}
+
+func (o *operation) add(step string, txHash common.Hash) {
+	o.steps = append(o.steps, fmt.Sprintf("%s: %s", step, utils.ExplorerTx(txHash.Hex())))
+}
+
+func (o *operation) String() string {
+	if len(o.steps) == 0 {
+		return "no transactions"
+	}
+	return strings.Join(o.steps, ", ")
+}
+
+// sendTransaction builds, signs and sends a transaction from the client's account
+func sendTransaction(
+	ctx context.Context,
+	client *internal.Client,
+	displayName string,
+	to string,
+	data []byte,
+	value *big.Int,
+) (*types.Transaction, error) {
+	tx, err := client.BuildTransaction(to, data, value)
+	if err != nil {
+		msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Problem with building transaction: %v\n",
+			global.CurrentProgress, global.TargetProgress, client.Account.AccountAddress, displayName, err,
+		)
+		log.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	return signAndSend(ctx, client, displayName, tx)
+}
+
+// sendTransactionWithNonce is sendTransaction for a caller that tracks nonces itself
+func sendTransactionWithNonce(
+	ctx context.Context,
+	client *internal.Client,
+	displayName string,
+	to string,
+	data []byte,
+	value *big.Int,
+	nonce uint64,
+) (*types.Transaction, error) {
+	tx, err := client.BuildTransactionWithNonce(to, data, value, nonce)
+	if err != nil {
+		msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Problem with building transaction: %v\n",
+			global.CurrentProgress, global.TargetProgress, client.Account.AccountAddress, displayName, err,
+		)
+		log.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	return signAndSend(ctx, client, displayName, tx)
+}
+
+func signAndSend(
+	ctx context.Context,
+	client *internal.Client,
+	displayName string,
+	tx *types.Transaction,
+) (*types.Transaction, error) {
+	accountAddress := client.Account.AccountAddress
+
+	signedTx, err := client.SignTx(ctx, tx)
+	if err != nil {
+		msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Problem with signing tx: %v\n",
+			global.CurrentProgress, global.TargetProgress, accountAddress, displayName, err,
+		)
+		log.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	err = client.Rpc.SendTransaction(ctx, signedTx)
+	if err != nil {
+		msg := fmt.Sprintf("[%d/%d] | %s | [%s] | Problem with sending tx: %v\n",
+			global.CurrentProgress, global.TargetProgress, accountAddress, displayName, err,
+		)
+		log.Error(msg)
+		return nil, errors.New(msg)
+	}
+
+	return signedTx, nil
+}
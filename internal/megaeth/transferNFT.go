@@ -0,0 +1,328 @@
+package megaeth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	log "github.com/sirupsen/logrus"
+
+	"main/internal"
+	"main/pkg/global"
+	accTypes "main/pkg/types"
+	"main/pkg/utils"
+)
+
+const (
+	// logBlockRange is the number of blocks requested per eth_getLogs call
+	logBlockRange = 10000
+	// transferNFTReceiptTimeout limits how long sent transfers are awaited
+	transferNFTReceiptTimeout = 3 * time.Minute
+)
+
+// erc721Tokens lists token IDs the account owns on an ERC-721 contract, through
+// ERC721Enumerable when supported and Transfer logs from fromBlock otherwise
+func erc721Tokens(ctx context.Context, client *internal.Client, contract common.Address, fromBlock uint64) ([]*big.Int, error) {
+	erc721ABI, err := utils.LoadABI(filepath.Join("abi", "erc721.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	account := client.Account.AccountAddress
+
+	out, err := callView(ctx, client, erc721ABI, contract, "balanceOf", account)
+	if err != nil {
+		return nil, fmt.Errorf("balanceOf: %w", err)
+	}
+
+	balance := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	if balance.Sign() == 0 {
+		return nil, nil
+	}
+
+	var tokens []*big.Int
+	for i := int64(0); i < balance.Int64(); i++ {
+		out, err = callView(ctx, client, erc721ABI, contract, "tokenOfOwnerByIndex", account, big.NewInt(i))
+		if err != nil {
+			break
+		}
+		tokens = append(tokens, abi.ConvertType(out[0], new(big.Int)).(*big.Int))
+	}
+
+	if err == nil {
+		return tokens, nil
+	}
+
+	log.Debugf("[%d/%d] | %s | [Transfer NFT] | %s is not enumerable, scanning Transfer logs: %v\n",
+		global.CurrentProgress, global.TargetProgress, account, contract, err,
+	)
+
+	return erc721TokensFromLogs(ctx, client, erc721ABI, contract, fromBlock)
+}
+
+func erc721TokensFromLogs(ctx context.Context, client *internal.Client, erc721ABI abi.ABI, contract common.Address, fromBlock uint64) ([]*big.Int, error) {
+	account := client.Account.AccountAddress
+
+	if fromBlock == 0 {
+		return nil, errors.New("collection is not enumerable and from_block is not set")
+	}
+
+	header, err := client.Rpc.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("latest block: %w", err)
+	}
+	latest := header.Number.Uint64()
+
+	transferTopic := erc721ABI.Events["Transfer"].ID
+	accountTopic := common.BytesToHash(account.Bytes())
+
+	seen := make(map[common.Hash]bool)
+	var candidates []*big.Int
+
+	for start := fromBlock; start <= latest; start += logBlockRange {
+		end := min(start+logBlockRange-1, latest)
+
+		logs, err := client.Rpc.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: []common.Address{contract},
+			Topics:    [][]common.Hash{{transferTopic}, nil, {accountTopic}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("eth_getLogs %d-%d: %w", start, end, err)
+		}
+
+		for _, entry := range logs {
+			if len(entry.Topics) != 4 || seen[entry.Topics[3]] {
+				continue
+			}
+			seen[entry.Topics[3]] = true
+			candidates = append(candidates, entry.Topics[3].Big())
+		}
+	}
+
+	// tokens received earlier may have been sent away since
+	var tokens []*big.Int
+	for _, tokenID := range candidates {
+		out, err := callView(ctx, client, erc721ABI, contract, "ownerOf", tokenID)
+		if err != nil {
+			continue
+		}
+		if owner, ok := out[0].(common.Address); ok && owner == account {
+			tokens = append(tokens, tokenID)
+		}
+	}
+
+	return tokens, nil
+}
+
+// erc1155Balances returns the IDs among ids the account holds and their amounts
+func erc1155Balances(ctx context.Context, client *internal.Client, contract common.Address, ids []*big.Int) ([]*big.Int, []*big.Int, error) {
+	erc1155ABI, err := utils.LoadABI(filepath.Join("abi", "erc1155.json"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accounts := make([]common.Address, len(ids))
+	for i := range accounts {
+		accounts[i] = client.Account.AccountAddress
+	}
+
+	out, err := callView(ctx, client, erc1155ABI, contract, "balanceOfBatch", accounts, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("balanceOfBatch: %w", err)
+	}
+
+	balances := *abi.ConvertType(out[0], new([]*big.Int)).(*[]*big.Int)
+
+	var heldIDs, amounts []*big.Int
+	for i, balance := range balances {
+		if balance.Sign() > 0 {
+			heldIDs = append(heldIDs, ids[i])
+			amounts = append(amounts, balance)
+		}
+	}
+
+	return heldIDs, amounts, nil
+}
+
+func parseTokenIDs(values []string) ([]*big.Int, error) {
+	ids := make([]*big.Int, 0, len(values))
+	for _, value := range values {
+		id, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid token id %q", value)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// TransferNFT moves every token the account holds on contracts.transfer_nft of the active network to transfer_nft.to
+func TransferNFT(ctx context.Context, accountData accTypes.AccountData) (bool, error) {
+	settings := global.Config.TransferNFT
+
+	if !common.IsHexAddress(settings.To) {
+		msg := fmt.Sprintf("[%d/%d] | %s | [Transfer NFT] | Invalid transfer_nft.to address %q\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, settings.To,
+		)
+		log.Error(msg)
+		return false, errors.New(msg)
+	}
+	to := common.HexToAddress(settings.To)
+
+	if to == accountData.AccountAddress {
+		return true, nil
+	}
+
+	client, ok := internal.GetClient(&accountData)
+	if !ok {
+		msg := fmt.Sprintf("[%d/%d] | %s | [Transfer NFT] | Problem with client initialization\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress,
+		)
+		log.Error(msg)
+		return false, errors.New(msg)
+	}
+
+	nonce, err := client.GetNonce()
+	if err != nil {
+		msg := fmt.Sprintf("[%d/%d] | %s | [Transfer NFT] | Problem with getting nonce: %v\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, err,
+		)
+		log.Error(msg)
+		return false, errors.New(msg)
+	}
+
+	op := &operation{}
+	var failed []string
+
+	type sentTransfer struct {
+		contract common.Address
+		tx       *types.Transaction
+	}
+	var sent []sentTransfer
+
+	for _, contractConfig := range global.Network.Contracts.TransferNFT {
+		contract := common.HexToAddress(contractConfig.Address)
+
+		var data [][]byte
+		var err error
+
+		switch strings.ToLower(contractConfig.Standard) {
+		case "", "erc721":
+			data, err = erc721TransferCalls(ctx, client, contract, to, contractConfig.FromBlock)
+		case "erc1155":
+			data, err = erc1155TransferCalls(ctx, client, contract, to, contractConfig.TokenIDs)
+		default:
+			err = fmt.Errorf("unknown standard %q", contractConfig.Standard)
+		}
+
+		if err != nil {
+			log.Errorf("[%d/%d] | %s | [Transfer NFT] | %s | %v\n",
+				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, contract, err,
+			)
+			failed = append(failed, contract.Hex())
+			continue
+		}
+
+		for _, calldata := range data {
+			tx, err := sendTransactionWithNonce(ctx, client, "Transfer NFT", contract.Hex(), calldata, big.NewInt(0), nonce)
+			if err != nil {
+				failed = append(failed, contract.Hex())
+				break
+			}
+			op.add(contract.Hex(), tx.Hash())
+			sent = append(sent, sentTransfer{contract: contract, tx: tx})
+			nonce++
+		}
+	}
+
+	// accepted by the node is not transferred yet, a reverted transfer fails its contract
+	waitCtx, cancel := context.WithTimeout(ctx, transferNFTReceiptTimeout)
+	defer cancel()
+
+	for _, transfer := range sent {
+		if _, err := client.WaitForTransaction(waitCtx, transfer.tx); err != nil {
+			log.Errorf("[%d/%d] | %s | [Transfer NFT] | %s | %v\n",
+				global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, transfer.contract, err,
+			)
+			if !slices.Contains(failed, transfer.contract.Hex()) {
+				failed = append(failed, transfer.contract.Hex())
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		msg := fmt.Sprintf("[%d/%d] | %s | [Transfer NFT] | Failed contracts: %s | Operation: %s\n",
+			global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, strings.Join(failed, ", "), op,
+		)
+		log.Error(msg)
+		return false, errors.New(msg)
+	}
+
+	log.Infof("[%d/%d] | %s | [Transfer NFT] | Transferred to %s | Operation: %s\n",
+		global.CurrentProgress, global.TargetProgress, accountData.AccountAddress, to, op,
+	)
+
+	return true, nil
+}
+
+func erc721TransferCalls(ctx context.Context, client *internal.Client, contract common.Address, to common.Address, fromBlock uint64) ([][]byte, error) {
+	erc721ABI, err := utils.LoadABI(filepath.Join("abi", "erc721.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := erc721Tokens(ctx, client, contract, fromBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	calls := make([][]byte, 0, len(tokens))
+	for _, tokenID := range tokens {
+		data, err := erc721ABI.Pack("safeTransferFrom", client.Account.AccountAddress, to, tokenID)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, data)
+	}
+
+	return calls, nil
+}
+
+func erc1155TransferCalls(ctx context.Context, client *internal.Client, contract common.Address, to common.Address, tokenIDs []string) ([][]byte, error) {
+	erc1155ABI, err := utils.LoadABI(filepath.Join("abi", "erc1155.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := parseTokenIDs(tokenIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, errors.New("token_ids are required for erc1155")
+	}
+
+	heldIDs, amounts, err := erc1155Balances(ctx, client, contract, ids)
+	if err != nil || len(heldIDs) == 0 {
+		return nil, err
+	}
+
+	data, err := erc1155ABI.Pack("safeBatchTransferFrom", client.Account.AccountAddress, to, heldIDs, amounts, []byte{})
+	if err != nil {
+		return nil, err
+	}
+
+	return [][]byte{data}, nil
+}
@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	log "github.com/sirupsen/logrus"
+
+	"main/pkg/global"
+	"main/pkg/utils"
+)
+
+// DefaultMulticall3Address is the address Multicall3 is deployed at on most chains
+var DefaultMulticall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+// multicallChunkSize is the number of calls packed into a single aggregate3 call
+const multicallChunkSize = 200
+
+// Call3 is a single call of aggregate3
+type Call3 struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// Call3Result is the outcome of a single call of aggregate3
+type Call3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// Multicall batches read-only calls into aggregate3 calls of Multicall3
+type Multicall struct {
+	rpc       RPC
+	address   common.Address
+	chunkSize int
+	abi       abi.ABI
+}
+
+func NewMulticall(rpc RPC, address common.Address) (*Multicall, error) {
+	multicallABI, err := utils.LoadABI(filepath.Join("abi", "multicall3.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Multicall{rpc: rpc, address: address, chunkSize: multicallChunkSize, abi: multicallABI}, nil
+}
+
+// Multicall returns the aggregator for the run's RPC at the address of the active network
+func (r *Run) Multicall() (*Multicall, error) {
+	address := DefaultMulticall3Address
+	if global.Network.Contracts.Multicall3 != "" {
+		address = common.HexToAddress(global.Network.Contracts.Multicall3)
+	}
+	return NewMulticall(r.Rpc, address)
+}
+
+// NewCall packs method of contractABI into a call that is allowed to fail
+func NewCall(contractABI abi.ABI, target common.Address, method string, args ...interface{}) (Call3, error) {
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return Call3{}, err
+	}
+	return Call3{Target: target, AllowFailure: true, CallData: data}, nil
+}
+
+// EthBalanceCall reads the native balance of address through Multicall3 itself
+func (m *Multicall) EthBalanceCall(address common.Address) Call3 {
+	data, _ := m.abi.Pack("getEthBalance", address)
+	return Call3{Target: m.address, AllowFailure: true, CallData: data}
+}
+
+// DecodeEthBalance unpacks the result of EthBalanceCall, nil when the call failed
+func (m *Multicall) DecodeEthBalance(result Call3Result) *big.Int {
+	if !result.Success {
+		return nil
+	}
+
+	out, err := m.abi.Unpack("getEthBalance", result.ReturnData)
+	if err != nil {
+		return nil
+	}
+	return abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+}
+
+// Aggregate3 executes calls in chunks and returns one result per call. When a
+// whole chunk fails, its calls are reported as failed and the rest still run.
+func (m *Multicall) Aggregate3(ctx context.Context, calls []Call3) ([]Call3Result, error) {
+	results := make([]Call3Result, len(calls))
+	failedChunks := 0
+
+	for start := 0; start < len(calls); start += m.chunkSize {
+		end := min(start+m.chunkSize, len(calls))
+
+		chunkResults, err := m.aggregateChunk(ctx, calls[start:end])
+		if err != nil {
+			log.Warnf("[Multicall] | Calls %d-%d of %d failed: %v\n", start+1, end, len(calls), err)
+			failedChunks++
+			continue
+		}
+
+		copy(results[start:end], chunkResults)
+	}
+
+	if len(calls) > 0 && failedChunks*m.chunkSize >= len(calls) {
+		return nil, fmt.Errorf("all %d multicall chunks failed", failedChunks)
+	}
+
+	return results, nil
+}
+
+func (m *Multicall) aggregateChunk(ctx context.Context, calls []Call3) ([]Call3Result, error) {
+	data, err := m.abi.Pack("aggregate3", calls)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := m.rpc.CallContract(ctx, ethereum.CallMsg{To: &m.address, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := m.abi.Unpack("aggregate3", output)
+	if err != nil {
+		return nil, err
+	}
+
+	results := *abi.ConvertType(out[0], new([]Call3Result)).(*[]Call3Result)
+	if len(results) != len(calls) {
+		return nil, fmt.Errorf("expected %d results, got %d", len(calls), len(results))
+	}
+
+	return results, nil
+}
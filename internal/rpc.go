@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// RPC is the subset of the node API that Client depends on.
+// *ethclient.Client satisfies it, as does every decorator returned by Wrap.
+type RPC interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethTypes.Header, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	SendTransaction(ctx context.Context, tx *ethTypes.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*ethTypes.Receipt, error)
+	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]ethTypes.Log, error)
+}
+
+// Invoker performs a single RPC call
+type Invoker func(ctx context.Context) error
+
+// Middleware wraps every call made through an RPC returned by Wrap.
+// method is the JSON-RPC method name of the call.
+type Middleware func(ctx context.Context, method string, next Invoker) error
+
+type wrappedRPC struct {
+	next  RPC
+	chain Middleware
+}
+
+// Wrap layers middlewares on top of rpc. The first middleware is the outermost one.
+func Wrap(rpc RPC, middlewares ...Middleware) RPC {
+	if len(middlewares) == 0 {
+		return rpc
+	}
+
+	return &wrappedRPC{next: rpc, chain: chainMiddlewares(middlewares)}
+}
+
+// chainMiddlewares composes middlewares into one, the first being the outermost
+func chainMiddlewares(middlewares []Middleware) Middleware {
+	chain := func(ctx context.Context, method string, next Invoker) error {
+		return next(ctx)
+	}
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		mw, inner := middlewares[i], chain
+		chain = func(ctx context.Context, method string, next Invoker) error {
+			return mw(ctx, method, func(ctx context.Context) error {
+				return inner(ctx, method, next)
+			})
+		}
+	}
+
+	return chain
+}
+
+func (w *wrappedRPC) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	var nonce uint64
+	err := w.chain(ctx, "eth_getTransactionCount", func(ctx context.Context) (err error) {
+		nonce, err = w.next.PendingNonceAt(ctx, account)
+		return err
+	})
+	return nonce, err
+}
+
+func (w *wrappedRPC) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	var balance *big.Int
+	err := w.chain(ctx, "eth_getBalance", func(ctx context.Context) (err error) {
+		balance, err = w.next.BalanceAt(ctx, account, blockNumber)
+		return err
+	})
+	return balance, err
+}
+
+func (w *wrappedRPC) ChainID(ctx context.Context) (*big.Int, error) {
+	var chainID *big.Int
+	err := w.chain(ctx, "eth_chainId", func(ctx context.Context) (err error) {
+		chainID, err = w.next.ChainID(ctx)
+		return err
+	})
+	return chainID, err
+}
+
+func (w *wrappedRPC) HeaderByNumber(ctx context.Context, number *big.Int) (*ethTypes.Header, error) {
+	var header *ethTypes.Header
+	err := w.chain(ctx, "eth_getBlockByNumber", func(ctx context.Context) (err error) {
+		header, err = w.next.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+func (w *wrappedRPC) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	var tip *big.Int
+	err := w.chain(ctx, "eth_maxPriorityFeePerGas", func(ctx context.Context) (err error) {
+		tip, err = w.next.SuggestGasTipCap(ctx)
+		return err
+	})
+	return tip, err
+}
+
+func (w *wrappedRPC) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	var gas uint64
+	err := w.chain(ctx, "eth_estimateGas", func(ctx context.Context) (err error) {
+		gas, err = w.next.EstimateGas(ctx, msg)
+		return err
+	})
+	return gas, err
+}
+
+func (w *wrappedRPC) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	var result []byte
+	err := w.chain(ctx, "eth_call", func(ctx context.Context) (err error) {
+		result, err = w.next.CallContract(ctx, msg, blockNumber)
+		return err
+	})
+	return result, err
+}
+
+func (w *wrappedRPC) SendTransaction(ctx context.Context, tx *ethTypes.Transaction) error {
+	return w.chain(ctx, "eth_sendRawTransaction", func(ctx context.Context) error {
+		return w.next.SendTransaction(ctx, tx)
+	})
+}
+
+func (w *wrappedRPC) TransactionReceipt(ctx context.Context, txHash common.Hash) (*ethTypes.Receipt, error) {
+	var receipt *ethTypes.Receipt
+	err := w.chain(ctx, "eth_getTransactionReceipt", func(ctx context.Context) (err error) {
+		receipt, err = w.next.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+func (w *wrappedRPC) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]ethTypes.Log, error) {
+	var logs []ethTypes.Log
+	err := w.chain(ctx, "eth_getLogs", func(ctx context.Context) (err error) {
+		logs, err = w.next.FilterLogs(ctx, query)
+		return err
+	})
+	return logs, err
+}
@@ -0,0 +1,220 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/rpc"
+	log "github.com/sirupsen/logrus"
+
+	"main/pkg/metrics"
+)
+
+// Logging writes every RPC call with its duration at debug level
+func Logging() Middleware {
+	return func(ctx context.Context, method string, next Invoker) error {
+		start := time.Now()
+		err := next(ctx)
+
+		if err != nil {
+			log.Debugf("[RPC] | %s | %v | Failed: %v\n", method, time.Since(start), err)
+		} else {
+			log.Debugf("[RPC] | %s | %v\n", method, time.Since(start))
+		}
+
+		return err
+	}
+}
+
+type MethodStats struct {
+	Calls   int64
+	Errors  int64
+	Latency time.Duration
+}
+
+// RPCStats accumulates per-method call counters
+type RPCStats struct {
+	mutex   sync.Mutex
+	methods map[string]*MethodStats
+}
+
+// DefaultRPCStats is shared by every client created with GetClient
+var DefaultRPCStats = &RPCStats{}
+
+func (s *RPCStats) record(method string, latency time.Duration, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.methods == nil {
+		s.methods = make(map[string]*MethodStats)
+	}
+
+	stats, ok := s.methods[method]
+	if !ok {
+		stats = &MethodStats{}
+		s.methods[method] = stats
+	}
+
+	stats.Calls++
+	stats.Latency += latency
+	if err != nil {
+		stats.Errors++
+	}
+}
+
+// Snapshot returns a copy of the collected counters
+func (s *RPCStats) Snapshot() map[string]MethodStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	snapshot := make(map[string]MethodStats, len(s.methods))
+	for method, stats := range s.methods {
+		snapshot[method] = *stats
+	}
+
+	return snapshot
+}
+
+// Log prints collected counters sorted by method name
+func (s *RPCStats) Log() {
+	snapshot := s.Snapshot()
+
+	methods := make([]string, 0, len(snapshot))
+	for method := range snapshot {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	for _, method := range methods {
+		stats := snapshot[method]
+		log.Infof("[RPC] | %s | Calls: %d | Errors: %d | Avg latency: %v\n",
+			method, stats.Calls, stats.Errors, stats.Latency/time.Duration(stats.Calls),
+		)
+	}
+}
+
+// Metrics records every call into stats, batch elements one by one
+func Metrics(stats *RPCStats) Middleware {
+	return func(ctx context.Context, method string, next Invoker) error {
+		start := time.Now()
+		err := next(ctx)
+		latency := time.Since(start)
+
+		forEachCall(ctx, method, err, func(method string, err error) {
+			stats.record(method, latency, err)
+		})
+		return err
+	}
+}
+
+// Prometheus exports every call of endpoint, batch elements one by one, and counts transactions accepted by the node
+func Prometheus(endpoint string) Middleware {
+	return func(ctx context.Context, method string, next Invoker) error {
+		start := time.Now()
+		err := next(ctx)
+		latency := time.Since(start)
+
+		forEachCall(ctx, method, err, func(method string, err error) {
+			metrics.ObserveRPC(method, endpoint, latency, err)
+
+			if method == "eth_sendRawTransaction" && err == nil {
+				metrics.TransactionsSent.Inc()
+			}
+		})
+		return err
+	}
+}
+
+// RateLimiter spaces calls evenly so that at most perSecond calls start each second
+type RateLimiter struct {
+	mutex    sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func NewRateLimiter(perSecond int) *RateLimiter {
+	return &RateLimiter{interval: time.Second / time.Duration(perSecond)}
+}
+
+// Wait blocks until the caller is allowed to make a call or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mutex.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mutex.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RateLimit delays calls so that all clients sharing limiter stay under its rate
+func RateLimit(limiter *RateLimiter) Middleware {
+	return func(ctx context.Context, method string, next Invoker) error {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+		return next(ctx)
+	}
+}
+
+// isRetryable reports whether a failed call may succeed when repeated.
+// Not found results and errors returned by the EVM itself are final.
+func isRetryable(err error) bool {
+	if errors.Is(err, ethereum.NotFound) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var dataErr rpc.DataError
+	return !errors.As(err, &dataErr)
+}
+
+// Retry repeats failed calls up to attempts times in total.
+// Transactions are never resent since the first attempt may have reached the node.
+func Retry(attempts int, delay time.Duration) Middleware {
+	return func(ctx context.Context, method string, next Invoker) error {
+		if method == "eth_sendRawTransaction" {
+			return next(ctx)
+		}
+
+		var err error
+		for i := 1; i <= attempts; i++ {
+			if err = next(ctx); err == nil || !isRetryable(err) {
+				return err
+			}
+
+			if i == attempts {
+				break
+			}
+
+			log.Debugf("[RPC] | %s | Attempt: [%d/%d] | Retry after %v: %v\n", method, i, attempts, delay, err)
+
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(delay):
+			}
+		}
+		return err
+	}
+}
@@ -0,0 +1,169 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	log "github.com/sirupsen/logrus"
+
+	"main/pkg/global"
+)
+
+// feeDataTTL is how long fetched fees are reused before asking the node again
+const feeDataTTL = 5 * time.Second
+
+type FeeData struct {
+	BaseFee   *big.Int
+	TipCap    *big.Int
+	FetchedAt time.Time
+}
+
+// MaxFee is the fee cap used for dynamic fee transactions: base fee plus tip
+func (f *FeeData) MaxFee() *big.Int {
+	return new(big.Int).Add(f.BaseFee, f.TipCap)
+}
+
+// Run holds the node connection and chain data shared by all workers of a run
+type Run struct {
+	Rpc   RPC
+	Batch Batcher
+	// Heads is nil when rpc.ws_url is not configured or not reachable
+	Heads *HeadWatcher
+
+	chainMutex sync.Mutex
+	chainID    *big.Int
+
+	feeMutex sync.Mutex
+	fees     *FeeData
+}
+
+var (
+	currentRun *Run
+	runErr     error
+	runOnce    sync.Once
+)
+
+// CurrentRun returns the run context, connecting to the first reachable RPC
+// of the active network and pre-fetching chain ID and fees on the first call
+func CurrentRun() (*Run, error) {
+	runOnce.Do(func() {
+		ctx := context.Background()
+
+		for _, url := range global.Network.RPC {
+			run, err := dialRun(ctx, url)
+			if err != nil {
+				log.Warnf("[%s] | Problem with connecting to RPC %s: %v\n", global.NetworkName, url, err)
+				runErr = err
+				continue
+			}
+
+			currentRun, runErr = run, nil
+			break
+		}
+
+		if currentRun == nil {
+			return
+		}
+
+		if global.Network.WSURL != "" {
+			var err error
+			if currentRun.Heads, err = NewHeadWatcher(context.Background(), global.Network.WSURL, currentRun.onHead); err != nil {
+				log.Warnf("Problem with connecting to WebSocket RPC, receipts will be polled: %v\n", err)
+			}
+		}
+	})
+
+	return currentRun, runErr
+}
+
+// dialRun connects to url and checks that it serves the chain of the active network
+func dialRun(ctx context.Context, url string) (*Run, error) {
+	rawClient, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := middlewares(url)
+	run := &Run{
+		Rpc:   Wrap(ethclient.NewClient(rawClient), chain...),
+		Batch: WrapBatcher(rawClient, chain...),
+	}
+
+	chainID, err := run.ChainID(ctx)
+	if err != nil {
+		rawClient.Close()
+		return nil, err
+	}
+
+	if expected := global.Network.ChainID; expected != 0 && chainID.Int64() != expected {
+		rawClient.Close()
+		return nil, fmt.Errorf("chain ID %v, expected %d", chainID, expected)
+	}
+
+	if _, err = run.Fees(ctx); err != nil {
+		rawClient.Close()
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// ChainID returns the chain ID, fetching it only once per run
+func (r *Run) ChainID(ctx context.Context) (*big.Int, error) {
+	r.chainMutex.Lock()
+	defer r.chainMutex.Unlock()
+
+	if r.chainID == nil {
+		chainID, err := r.Rpc.ChainID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r.chainID = chainID
+	}
+
+	return new(big.Int).Set(r.chainID), nil
+}
+
+// Fees returns current base fee and tip, refreshed at most once per feeDataTTL.
+// Header and tip are fetched in one batch request.
+func (r *Run) Fees(ctx context.Context) (*FeeData, error) {
+	r.feeMutex.Lock()
+	defer r.feeMutex.Unlock()
+
+	if fees := r.freshFees(); fees != nil {
+		return fees, nil
+	}
+
+	elems, decode := feeElems()
+	if err := r.Batch.BatchCallContext(ctx, elems); err != nil {
+		return nil, err
+	}
+
+	fees, err := decode()
+	if err != nil {
+		return nil, err
+	}
+
+	r.storeFees(fees)
+
+	return fees, nil
+}
+
+// freshFees returns cached fees unless they are older than feeDataTTL. feeMutex must be held.
+func (r *Run) freshFees() *FeeData {
+	if r.fees != nil && time.Since(r.fees.FetchedAt) < feeDataTTL {
+		return r.fees
+	}
+	return nil
+}
+
+// storeFees caches fees fetched as part of another batch. feeMutex must be held.
+func (r *Run) storeFees(fees *FeeData) {
+	fees.FetchedAt = time.Now()
+	r.fees = fees
+}
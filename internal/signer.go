@@ -0,0 +1,231 @@
+package internal
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/valyala/fasthttp"
+
+	"main/pkg/global"
+	"main/pkg/types"
+)
+
+// Signer signs transactions on behalf of a single address
+type Signer interface {
+	Address() common.Address
+	SignTx(ctx context.Context, tx *ethTypes.Transaction, chainID *big.Int) (*ethTypes.Transaction, error)
+}
+
+// LocalSigner signs with a private key held in memory
+type LocalSigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+func NewLocalSigner(key *ecdsa.PrivateKey, address common.Address) *LocalSigner {
+	return &LocalSigner{key: key, address: address}
+}
+
+func (s *LocalSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *LocalSigner) SignTx(_ context.Context, tx *ethTypes.Transaction, chainID *big.Int) (*ethTypes.Transaction, error) {
+	return ethTypes.SignTx(tx, ethTypes.NewLondonSigner(chainID), s.key)
+}
+
+// ClefSigner asks an external signer speaking Clef's account_signTransaction JSON-RPC method
+type ClefSigner struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+func NewClefSigner(client *rpc.Client, address common.Address) *ClefSigner {
+	return &ClefSigner{client: client, address: address}
+}
+
+func (s *ClefSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *ClefSigner) SignTx(ctx context.Context, tx *ethTypes.Transaction, chainID *big.Int) (*ethTypes.Transaction, error) {
+	if tx.Type() != ethTypes.DynamicFeeTxType {
+		return nil, fmt.Errorf("unsupported tx type %d", tx.Type())
+	}
+
+	data := hexutil.Bytes(tx.Data())
+	accessList := tx.AccessList()
+
+	var to *common.MixedcaseAddress
+	if tx.To() != nil {
+		address := common.NewMixedcaseAddress(*tx.To())
+		to = &address
+	}
+
+	args := &apitypes.SendTxArgs{
+		From:                 common.NewMixedcaseAddress(s.address),
+		To:                   to,
+		Gas:                  hexutil.Uint64(tx.Gas()),
+		MaxFeePerGas:         (*hexutil.Big)(tx.GasFeeCap()),
+		MaxPriorityFeePerGas: (*hexutil.Big)(tx.GasTipCap()),
+		Value:                hexutil.Big(*tx.Value()),
+		Nonce:                hexutil.Uint64(tx.Nonce()),
+		Input:                &data,
+		AccessList:           &accessList,
+		ChainID:              (*hexutil.Big)(chainID),
+	}
+
+	var result struct {
+		Raw hexutil.Bytes `json:"raw"`
+	}
+
+	if err := s.client.CallContext(ctx, &result, "account_signTransaction", args); err != nil {
+		return nil, err
+	}
+
+	return decodeSignedTx(result.Raw, s.address, chainID)
+}
+
+// HTTPSigner posts the unsigned transaction to a signing service:
+//
+//	POST {url}/sign {"address": "0x..", "chainId": "0x..", "tx": "0x<unsigned tx>"}
+//	-> {"signedTx": "0x<signed tx>"}
+type HTTPSigner struct {
+	client    *fasthttp.Client
+	url       string
+	authToken string
+	address   common.Address
+}
+
+func NewHTTPSigner(url string, authToken string, address common.Address) *HTTPSigner {
+	return &HTTPSigner{
+		client:    &fasthttp.Client{ReadTimeout: 30 * time.Second, WriteTimeout: 10 * time.Second},
+		url:       strings.TrimSuffix(url, "/"),
+		authToken: authToken,
+		address:   address,
+	}
+}
+
+func (s *HTTPSigner) Address() common.Address {
+	return s.address
+}
+
+type httpSignRequest struct {
+	Address common.Address `json:"address"`
+	ChainID *hexutil.Big   `json:"chainId"`
+	Tx      hexutil.Bytes  `json:"tx"`
+}
+
+type httpSignResponse struct {
+	SignedTx hexutil.Bytes `json:"signedTx"`
+	Error    string        `json:"error"`
+}
+
+func (s *HTTPSigner) SignTx(ctx context.Context, tx *ethTypes.Transaction, chainID *big.Int) (*ethTypes.Transaction, error) {
+	unsigned, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(httpSignRequest{
+		Address: s.address,
+		ChainID: (*hexutil.Big)(chainID),
+		Tx:      unsigned,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(s.url + "/sign")
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.Header.SetContentType("application/json")
+	if s.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.authToken)
+	}
+	req.SetBody(payload)
+
+	timeout := 30 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	if err = s.client.DoTimeout(req, resp, timeout); err != nil {
+		return nil, err
+	}
+
+	var result httpSignResponse
+	if err = json.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, fmt.Errorf("unexpected signer response (status %d): %w", resp.StatusCode(), err)
+	}
+
+	if resp.StatusCode() != fasthttp.StatusOK || result.Error != "" {
+		return nil, fmt.Errorf("signer refused transaction (status %d): %s", resp.StatusCode(), result.Error)
+	}
+
+	return decodeSignedTx(result.SignedTx, s.address, chainID)
+}
+
+// decodeSignedTx makes sure a remote signer returned a transaction signed by the expected address
+func decodeSignedTx(raw []byte, address common.Address, chainID *big.Int) (*ethTypes.Transaction, error) {
+	signedTx := new(ethTypes.Transaction)
+	if err := signedTx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("invalid signed transaction: %w", err)
+	}
+
+	sender, err := ethTypes.Sender(ethTypes.NewLondonSigner(chainID), signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	if sender != address {
+		return nil, fmt.Errorf("transaction signed by %s instead of %s", sender, address)
+	}
+
+	return signedTx, nil
+}
+
+var (
+	clefClient *rpc.Client
+	clefErr    error
+	clefOnce   sync.Once
+)
+
+// NewSigner creates the signer backend configured in config.yaml for the account
+func NewSigner(accountData *types.AccountData) (Signer, error) {
+	switch global.Config.Signer.Type {
+	case "", "local":
+		if accountData.AccountKey == nil {
+			return nil, errors.New("local signer requires a private key")
+		}
+		return NewLocalSigner(accountData.AccountKey, accountData.AccountAddress), nil
+	case "clef":
+		clefOnce.Do(func() {
+			clefClient, clefErr = rpc.DialContext(context.Background(), global.Config.Signer.URL)
+		})
+		if clefErr != nil {
+			return nil, clefErr
+		}
+		return NewClefSigner(clefClient, accountData.AccountAddress), nil
+	case "http":
+		return NewHTTPSigner(global.Config.Signer.URL, global.Config.Signer.AuthToken, accountData.AccountAddress), nil
+	default:
+		return nil, fmt.Errorf("unknown signer type: %s", global.Config.Signer.Type)
+	}
+}
@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// resubscribeDelay is the pause before subscribing again after the subscription dropped
+	resubscribeDelay = 5 * time.Second
+	// wsReceiptPollInterval is the safety poll used while new heads arrive over WebSocket
+	wsReceiptPollInterval = 15 * time.Second
+)
+
+// HeadWatcher follows new blocks over a WebSocket connection and wakes
+// goroutines waiting for the next head. While the subscription is down
+// Next reports it inactive and waiters fall back to HTTP polling.
+type HeadWatcher struct {
+	client *ethclient.Client
+	onHead func(*ethTypes.Header)
+
+	mutex  sync.Mutex
+	active bool
+	next   chan struct{}
+}
+
+// NewHeadWatcher dials url and keeps a newHeads subscription alive until ctx is done.
+// onHead is called for every received header.
+func NewHeadWatcher(ctx context.Context, url string, onHead func(*ethTypes.Header)) (*HeadWatcher, error) {
+	client, err := ethclient.DialContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher := &HeadWatcher{client: client, onHead: onHead, next: make(chan struct{})}
+	go watcher.run(ctx)
+
+	return watcher, nil
+}
+
+func (h *HeadWatcher) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		headers := make(chan *ethTypes.Header, 16)
+
+		sub, err := h.client.SubscribeNewHead(ctx, headers)
+		if err != nil {
+			log.Warnf("[HeadWatcher] | Problem with subscribing to new heads: %v\n", err)
+			h.setActive(false)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(resubscribeDelay):
+			}
+			continue
+		}
+
+		h.setActive(true)
+		log.Debugf("[HeadWatcher] | Subscribed to new heads\n")
+
+		h.follow(ctx, sub, headers)
+		sub.Unsubscribe()
+	}
+}
+
+func (h *HeadWatcher) follow(ctx context.Context, sub ethereum.Subscription, headers <-chan *ethTypes.Header) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-sub.Err():
+			log.Warnf("[HeadWatcher] | Subscription dropped, falling back to polling: %v\n", err)
+			h.setActive(false)
+			return
+		case header := <-headers:
+			if h.onHead != nil {
+				h.onHead(header)
+			}
+			h.broadcast()
+		}
+	}
+}
+
+func (h *HeadWatcher) setActive(active bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.active = active
+}
+
+func (h *HeadWatcher) broadcast() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	close(h.next)
+	h.next = make(chan struct{})
+}
+
+// Next returns a channel closed when the next head arrives and whether the subscription is alive
+func (h *HeadWatcher) Next() (<-chan struct{}, bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.next, h.active
+}
+
+// SubscribeLogs streams logs matching query over the WebSocket connection
+func (h *HeadWatcher) SubscribeLogs(ctx context.Context, query ethereum.FilterQuery, logs chan<- ethTypes.Log) (ethereum.Subscription, error) {
+	return h.client.SubscribeFilterLogs(ctx, query, logs)
+}
+
+// onHead keeps the cached base fee in step with new blocks; the tip is still refreshed per feeDataTTL
+func (r *Run) onHead(header *ethTypes.Header) {
+	if header.BaseFee == nil {
+		return
+	}
+
+	r.feeMutex.Lock()
+	defer r.feeMutex.Unlock()
+
+	if r.fees != nil {
+		r.fees = &FeeData{BaseFee: header.BaseFee, TipCap: r.fees.TipCap, FetchedAt: r.fees.FetchedAt}
+	}
+}
@@ -3,24 +3,38 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
+	"math/big"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	log "github.com/sirupsen/logrus"
 
+	"main/internal"
 	"main/internal/megaeth"
 	"main/pkg/global"
+	"main/pkg/metrics"
+	"main/pkg/state"
 	"main/pkg/types"
 	"main/pkg/utils"
 )
 
+// configPath and configOverrides are set by -config and -set
+var (
+	configPath      = filepath.Join("config", "config.yaml")
+	configOverrides []string
+)
+
 func inputUser(inputText string) string {
 	if inputText != "" {
 		fmt.Print(inputText)
@@ -53,21 +67,63 @@ func processAccount(
 	) {
 	defer wg.Done()
 	defer func() { <-sem }()
-	func_obj(ctx, account)
+
+	metrics.WorkersInFlight.Inc()
+	defer metrics.WorkersInFlight.Dec()
+
+	success, err := func_obj(ctx, account)
+	recordModuleRun(account, success, err)
+
+	result := "success"
+	switch {
+	case errors.Is(err, types.ErrSkipped):
+		result = "skipped"
+	case !success || err != nil:
+		result = "failed"
+	}
+	metrics.AccountsProcessed.WithLabelValues(global.Module, result).Inc()
+}
+
+// recordModuleRun saves the outcome of the selected module in the state file for -state selectors
+func recordModuleRun(account types.AccountData, success bool, err error) {
+	run := state.ModuleRun{Success: success && err == nil, FinishedAt: time.Now()}
+	if errors.Is(err, types.ErrSkipped) {
+		run.Skipped = true
+	} else if err != nil {
+		run.Error = strings.TrimSpace(err.Error())
+	}
+
+	store, err := state.Default(global.Config.StatePath)
+	if err == nil {
+		err = store.Update(account.AccountAddress, func(stored *state.Account) {
+			if stored.Modules == nil {
+				stored.Modules = make(map[string]state.ModuleRun)
+			}
+			stored.Modules[global.Module] = run
+		})
+	}
+
+	if err != nil {
+		log.Warnf("%s | Problem with saving state file: %v\n", account.AccountAddress, err)
+	}
 }
 
 func processAccounts(func_obj types.ModuleFunction, threads int) {
 	wg := &sync.WaitGroup{}
 	sem := make(chan struct{}, threads)
 	ctx := context.Background()
-	ticker := time.NewTicker(
-		time.Duration(global.Config.DelayBetweenAccs.Min) * time.Second,
-	)
-	defer ticker.Stop()
+
+	// a zero delay starts accounts back to back, NewTicker rejects it
+	var tick <-chan time.Time
+	if delay := time.Duration(global.Config.DelayBetweenAccs.Min) * time.Second; delay > 0 {
+		ticker := time.NewTicker(delay)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
 
 	for i, account := range global.AccountsList {
-		if i >= 1 {
-			<-ticker.C
+		if i >= 1 && tick != nil {
+			<-tick
 			log.Infof("Sleep %d seconds ...", global.Config.DelayBetweenAccs.Min)
 		}
 
@@ -80,6 +136,125 @@ func processAccounts(func_obj types.ModuleFunction, threads int) {
 	wg.Wait()
 }
 
+// logAccountStates prints a summary of balances and nonces of loaded accounts, fetched in batches
+func logAccountStates(run *internal.Run) {
+	addresses := make([]common.Address, len(global.AccountsList))
+	for i, account := range global.AccountsList {
+		addresses[i] = account.AccountAddress
+	}
+
+	total := big.NewInt(0)
+	var empty, unused, failed int
+
+	for _, accountState := range run.LoadAccountStates(context.Background(), addresses) {
+		if accountState.Err != nil {
+			failed++
+			continue
+		}
+		total.Add(total, accountState.Balance)
+		if accountState.Balance.Sign() == 0 {
+			empty++
+		}
+		if accountState.Nonce == 0 {
+			unused++
+		}
+	}
+
+	log.Printf("Accounts: %d | Total balance: %s ETH | Empty: %d | Without transactions: %d | Not loaded: %d\n",
+		len(addresses), utils.FormatEther(total), empty, unused, failed,
+	)
+}
+
+// initProxies builds the proxy pool from paths.proxies (a direct client without proxies),
+// checks every proxy and keeps re-checking them in background
+func initProxies() {
+	err := utils.InitProxies(global.Config.Paths.Proxies)
+
+	if err != nil {
+		log.Panicf("Error initializing proxies: %s\n", err)
+	}
+
+	settings := global.Config.ProxyCheck
+	utils.Pool = utils.NewProxyPool(utils.Proxies, settings.URL, time.Duration(settings.Timeout)*time.Second)
+
+	if len(utils.Proxies) > 0 {
+		ctx := context.Background()
+		utils.Pool.CheckAll(ctx)
+		utils.Pool.LogSummary()
+		utils.Pool.StartHealthChecks(ctx, time.Duration(settings.Interval)*time.Second)
+	}
+}
+
+// loadAccounts reads paths.accounts into global.AccountsList, or paths.private_keys when it does not exist
+func loadAccounts() {
+	accountsPath := global.Config.Paths.Accounts
+
+	if _, err := os.Stat(accountsPath); err == nil {
+		global.AccountsList, err = utils.LoadAccountsFile(accountsPath)
+
+		if err != nil {
+			log.Panicln(err.Error())
+		}
+	} else {
+		accountsListString, err := utils.ReadFileByRows(global.Config.Paths.PrivateKeys)
+
+		if err != nil {
+			log.Panicln(err.Error())
+		}
+
+		global.AccountsList, err = utils.GetAccounts(accountsListString, false)
+
+		if err != nil {
+			log.Panicln(err.Error())
+		}
+	}
+
+	log.Printf("Successfully Loaded %d Accounts\n", len(global.AccountsList))
+
+	// narrow down to accounts chosen on the command line
+	if !selector.empty() {
+		selected, err := selector.apply(context.Background(), global.AccountsList)
+
+		if err != nil {
+			log.Panicf("Error selecting accounts: %s\n", err)
+		}
+
+		log.Printf("Selected %d of %d Accounts\n", len(selected), len(global.AccountsList))
+		global.AccountsList = selected
+	}
+
+	global.TargetProgress = int64(len(global.AccountsList))
+}
+
+// shuffleAccounts shuffles global.AccountsList when shuffle_accs is set. Read-only
+// reports keep the order of the accounts file.
+func shuffleAccounts() {
+	if !global.Config.ShuffleAccs {
+		return
+	}
+
+	rand.NewSource(time.Now().Unix())
+	rand.Shuffle(len(global.AccountsList), func(i, j int) {
+		global.AccountsList[i], global.AccountsList[j] = global.AccountsList[j], global.AccountsList[i]
+	})
+}
+
+// skipAccounts leaves out accounts whose skip_modules contain the selected module
+func skipAccounts() {
+	accounts := make([]types.AccountData, 0, len(global.AccountsList))
+
+	for _, account := range global.AccountsList {
+		if slices.Contains(account.SkipModules, global.Module) {
+			log.Infof("%s | Module %s is skipped for the account\n", account.AccountAddress, global.Module)
+			continue
+		}
+		accounts = append(accounts, account)
+	}
+
+	global.AccountsList = accounts
+	global.TargetProgress = int64(len(accounts))
+}
+
 func initLog() {
 	log.SetFormatter(&log.TextFormatter{
 		ForceColors:     true,
@@ -94,8 +269,27 @@ func main() {
 	// init log
 	initLog()
 
+	// config and account selector flags come before the command
+	flag.StringVar(&configPath, "config", configPath, "path of config.yaml")
+	flag.Func("set", "override a config.yaml key, e.g. -set faucet.cooldown=12 (repeatable)", func(override string) error {
+		configOverrides = append(configOverrides, override)
+		return nil
+	})
+	selector.register(flag.CommandLine)
+	flag.Parse()
+
+	// config commands report problems of config.yaml themselves
+	if flag.Arg(0) == "config" {
+		runCommand(flag.Args())
+		return
+	}
+
 	// parse config.yaml file
-	utils.ParseConfig(filepath.Join("config", "config.yaml"))
+	utils.ParseConfig(configPath, configOverrides)
+
+	if global.Config.Metrics.Listen != "" {
+		metrics.Serve(global.Config.Metrics.Listen)
+	}
 
 	wr, err := os.OpenFile(filepath.Join("log.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 
@@ -115,42 +309,21 @@ func main() {
 	// handle panic
 	defer handlePanic()
 
-	// init Proxies
-	err = utils.InitProxies(filepath.Join("config", "proxies.txt"))
-
-	if err != nil {
-		log.Panicf("Error initializing proxies: %s\n", err)
-	}
-
-	if len(utils.Proxies) <= 0 {
-		global.Clients = append(global.Clients, utils.CreateClient(""))
-	} else {
-		for _, proxy := range utils.Proxies {
-			global.Clients = append(global.Clients, utils.CreateClient(proxy))
-		}
-	}
-
-	accountsListString, err := utils.ReadFileByRows(filepath.Join("config", "private_keys.txt"))
-
-	if err != nil {
-		log.Panicln(err.Error())
+	// pick network profile of the run
+	if err = utils.SelectNetwork(); err != nil {
+		log.Panicf("Error selecting network: %s\n", err)
 	}
+	log.Printf("Network: %s\n", global.NetworkName)
 
-	global.AccountsList, err = utils.GetAccounts(accountsListString, false)
-
-	if err != nil {
-		log.Panicln(err.Error())
+	// run command passed as argument instead of interactive menu
+	if flag.NArg() > 0 {
+		runCommand(flag.Args())
+		return
 	}
 
-	log.Printf("Successfully Loaded %d Accounts\n", len(global.AccountsList))
-
-	// shuffle accounts
-	if global.Config.ShuffleAccs {
-		rand.NewSource(time.Now().Unix())
-		rand.Shuffle(len(global.AccountsList), func(i, j int) {
-			global.AccountsList[i], global.AccountsList[j] = global.AccountsList[j], global.AccountsList[i]
-		})
-	}
+	initProxies()
+	loadAccounts()
+	shuffleAccounts()
 
 	inputUserData := inputUser("\nThreads: ")
 	threads, err := strconv.Atoi(inputUserData)
@@ -160,10 +333,17 @@ func main() {
 	}
 
 	fmt.Printf("\n")
-	global.TargetProgress = int64(len(accountsListString))
 
 	// build CLI
 	utils.Cli()
+	skipAccounts()
+
+	// connect to RPC and pre-fetch chain data shared by all threads
+	run, err := internal.CurrentRun()
+	if err != nil {
+		log.Panicf("Error connecting to RPC: %s\n", err)
+	}
+	logAccountStates(run)
 
 	// sleep before start
 	delayMin, delayMax := global.Config.DelayBeforeStart.Min, global.Config.DelayBeforeStart.Max
@@ -172,22 +352,39 @@ func main() {
 	switch global.Module {
 	case "Faucet test tokens":
 		processAccounts(megaeth.FaucetTokens, threads)
-	case "Mint Megamafia NFT",
-		 "Mint FUN Starts NFT",
-		 "Mint Mega Cat NFT",
-		 "Mint Blackhole NFT",
-		 "Mint Xyroph NFT",
-		 "Mint Lord Lapin NFT",
-		 "Mint Angry Monkeys",
-		 "Mint Bloom NFT":
+	case "Sweep ETH to master wallet":
+		processAccounts(megaeth.Sweep, threads)
+		megaeth.PrintSweepReport(os.Stdout)
+	case "Transfer NFTs to master wallet":
+		processAccounts(megaeth.TransferNFT, threads)
+	case "Fund wallets":
+		ctx := context.Background()
+		plan, err := megaeth.PlanFunding(ctx, global.AccountsList)
+		if err != nil {
+			log.Panic(err)
+		}
+
+		plan.Print(os.Stdout)
+
+		if len(plan.Transfers) > 0 && strings.EqualFold(inputUser("\nSend transfers? [y/N]: "), "y") {
+			if err = plan.Execute(ctx); err != nil {
+				log.Errorf("Funding failed: %s\n", err)
+			}
+		}
+	default:
 		minter, err := megaeth.GetInterfaceByModuleName()
 		if err != nil {
 			log.Panic(err)
 		}
+		global.AccountsList = megaeth.Preflight(context.Background(), minter, global.AccountsList)
+		global.TargetProgress = int64(len(global.AccountsList))
+
 		func_obj := megaeth.StartMint(minter)
 		processAccounts(func_obj, threads)
 	}
 
+	internal.DefaultRPCStats.Log()
+
 	log.Printf("The Work Has Been Successfully Finished\n")
 	inputUser("\nPress Enter to Exit..")
 }
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+const namespace = "megaeth"
+
+var (
+	TransactionsSent = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_sent_total",
+		Help:      "Transactions accepted by the RPC node.",
+	})
+	TransactionsMined = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_mined_total",
+		Help:      "Waited for transactions mined successfully.",
+	})
+	TransactionsReverted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_reverted_total",
+		Help:      "Waited for transactions mined with failed status.",
+	})
+	InclusionSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "transaction_inclusion_seconds",
+		Help:      "Time from sending a transaction until its receipt is seen.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 30, 60, 120, 300},
+	})
+
+	FaucetClaims = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "faucet_claims_total",
+		Help:      "Faucet claims by result: paid, claimed_unpaid, cooldown, captcha, rejected, failed.",
+	}, []string{"result"})
+
+	RPCCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_calls_total",
+		Help:      "RPC calls by method and endpoint host.",
+	}, []string{"method", "endpoint"})
+	RPCErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_errors_total",
+		Help:      "Failed RPC calls by method and endpoint host.",
+	}, []string{"method", "endpoint"})
+	RPCLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_latency_seconds",
+		Help:      "RPC call latency by method and endpoint host.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "endpoint"})
+
+	WorkersInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "workers_in_flight",
+		Help:      "Accounts being processed right now.",
+	})
+	AccountsProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "accounts_processed_total",
+		Help:      "Accounts finished by module and result: success, failed, skipped.",
+	}, []string{"module", "result"})
+)
+
+// ObserveRPC records one RPC call
+func ObserveRPC(method string, endpoint string, latency time.Duration, err error) {
+	RPCCalls.WithLabelValues(method, endpoint).Inc()
+	RPCLatency.WithLabelValues(method, endpoint).Observe(latency.Seconds())
+	if err != nil {
+		RPCErrors.WithLabelValues(method, endpoint).Inc()
+	}
+}
+
+// Serve exposes /metrics on listen in background
+func Serve(listen string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	server := &http.Server{Addr: listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("[Metrics] | Problem with serving /metrics on %s: %v\n", listen, err)
+		}
+	}()
+
+	log.Printf("Metrics: http://%s/metrics\n", listen)
+}
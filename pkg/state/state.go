@@ -0,0 +1,154 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultPath is where account state is kept when state_path is not set
+const DefaultPath = "state.json"
+
+// Faucet claim statuses
+const (
+	FaucetPaid          = "paid"
+	FaucetClaimedUnpaid = "claimed-unpaid"
+)
+
+// Faucet is the outcome of the last faucet claim of an account
+type Faucet struct {
+	Status    string    `json:"status"`
+	ClaimedAt time.Time `json:"claimed_at"`
+	Amount    string    `json:"amount,omitempty"`
+	// PayoutSeconds is how long the payout took to appear on chain
+	PayoutSeconds float64 `json:"payout_seconds,omitempty"`
+	// CooldownUntil is when the faucet accepts the next claim, taken from its rejection message
+	CooldownUntil time.Time `json:"cooldown_until,omitzero"`
+}
+
+// NextClaim is the earliest time the faucet accepts another claim given its cooldown
+func (f Faucet) NextClaim(cooldown time.Duration) time.Time {
+	next := f.CooldownUntil
+	if f.Status != "" && f.ClaimedAt.Add(cooldown).After(next) {
+		next = f.ClaimedAt.Add(cooldown)
+	}
+	return next
+}
+
+// ModuleRun is the outcome of the last run of a module for an account
+type ModuleRun struct {
+	Success bool `json:"success"`
+	// Skipped is set when the module left the account alone on purpose, e.g. inside the faucet cooldown
+	Skipped    bool      `json:"skipped,omitempty"`
+	FinishedAt time.Time `json:"finished_at"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Account is everything remembered about an address between runs
+type Account struct {
+	Faucet Faucet `json:"faucet,omitzero"`
+	// Proxy is the proxy the account was assigned, kept so it never changes IP between runs
+	Proxy string `json:"proxy,omitempty"`
+	// Modules are the last runs keyed by module name
+	Modules map[string]ModuleRun `json:"modules,omitempty"`
+}
+
+// Store keeps account state in a JSON file, saved after every update
+type Store struct {
+	path     string
+	mutex    sync.Mutex
+	accounts map[string]Account
+}
+
+var (
+	defaultStore *Store
+	defaultErr   error
+	defaultOnce  sync.Once
+)
+
+// Open loads the store at path; a missing file is an empty store
+func Open(path string) (*Store, error) {
+	store := &Store{path: path, accounts: make(map[string]Account)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &store.accounts); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Default returns the store at path, opening it on the first call
+func Default(path string) (*Store, error) {
+	defaultOnce.Do(func() {
+		if path == "" {
+			path = DefaultPath
+		}
+		defaultStore, defaultErr = Open(path)
+	})
+
+	return defaultStore, defaultErr
+}
+
+func key(address common.Address) string {
+	return strings.ToLower(address.Hex())
+}
+
+// Get returns the state of address, zero when nothing is recorded
+func (s *Store) Get(address common.Address) Account {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.accounts[key(address)]
+}
+
+// Update applies fn to the state of address and saves the store
+func (s *Store) Update(address common.Address, fn func(*Account)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	account := s.accounts[key(address)]
+	fn(&account)
+	s.accounts[key(address)] = account
+
+	return s.save()
+}
+
+// save writes the store through a temporary file so a crash never leaves it truncated
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.accounts, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"fmt"
+	"math/rand"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Range is an inclusive [Min, Max] interval. In YAML it is written either as
+// a single number (fixed value) or as a mapping with min and max keys.
+type Range struct {
+	Min int64 `yaml:"min"`
+	Max int64 `yaml:"max"`
+}
+
+func (r *Range) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		var fixed int64
+		if err := value.Decode(&fixed); err != nil {
+			return err
+		}
+		r.Min, r.Max = fixed, fixed
+		return nil
+	}
+
+	// Decode starts a decoder without KnownFields, so keys are checked here
+	if value.Kind == yaml.MappingNode {
+		for i := 0; i < len(value.Content); i += 2 {
+			if key := value.Content[i]; key.Value != "min" && key.Value != "max" {
+				return fmt.Errorf("line %d: unknown key %s, expected min and max", key.Line, key.Value)
+			}
+		}
+	}
+
+	type plain Range
+	if err := value.Decode((*plain)(r)); err != nil {
+		return err
+	}
+
+	if r.Max < r.Min {
+		return fmt.Errorf("line %d: max (%d) is less than min (%d)", value.Line, r.Max, r.Min)
+	}
+
+	return nil
+}
+
+// IsZero reports whether the range was not set
+func (r Range) IsZero() bool {
+	return r.Min == 0 && r.Max == 0
+}
+
+// Random returns a random value in the range
+func (r Range) Random() int64 {
+	if r.Max <= r.Min {
+		return r.Min
+	}
+	return r.Min + rand.Int63n(r.Max-r.Min+1)
+}
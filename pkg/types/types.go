@@ -3,6 +3,7 @@ package types
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -10,13 +11,30 @@ import (
 
 type ModuleFunction func(context.Context, AccountData) (bool, error)
 
+// ErrSkipped is returned by a module that left the account alone on purpose,
+// e.g. inside the faucet cooldown. The account is neither a success nor a failure.
+var ErrSkipped = errors.New("skipped")
+
 type AccountData struct {
 	AccountKeyHex  string
 	AccountKey     *ecdsa.PrivateKey
 	AccountAddress common.Address
 	AccountLogData string
+	// Proxy pins the account to a proxy; empty means one is assigned from proxies.txt
+	Proxy string
+
+	// Label and Group name the account in accounts.yaml
+	Label string
+	Group string
+	// MaxValue caps the ETH value of every transaction the account signs, nil means no cap
+	MaxValue *big.Int
+	// SkipModules are module names the account is left out of
+	SkipModules []string
 }
 
+// ClaimStruct is the allowlist proof argument of claim. Fields follow thirdweb's
+// AllowlistProof layout: LeafIndex is quantityLimitPerWallet, LeafAmount is
+// pricePerToken and LeafAddress is currency of the allowlist entry.
 type ClaimStruct struct {
 	Proof       []common.Hash
 	LeafIndex   *big.Int
@@ -24,7 +42,78 @@ type ClaimStruct struct {
 	LeafAddress common.Address
 }
 
+// DropConfig describes a mint module. Drops with Data set are minted by sending
+// the raw calldata, all others through the claim function.
+type DropConfig struct {
+	Name      string `yaml:"name"`
+	Contract  string `yaml:"contract"`
+	Price     string `yaml:"price"`
+	Currency  string `yaml:"currency"`
+	Quantity  Range  `yaml:"quantity"`
+	Signature string `yaml:"signature"`
+	Data      string `yaml:"data"`
+
+	ExactApprove bool   `yaml:"exact_approve"`
+	Allowlist    string `yaml:"allowlist"`
+}
+
+// NetworkConfig is a named chain profile: endpoints, faucet and contracts of one network
+type NetworkConfig struct {
+	RPC      []string `yaml:"rpc"`
+	WSURL    string   `yaml:"ws_url"`
+	ChainID  int64    `yaml:"chain_id"`
+	Explorer string   `yaml:"explorer"`
+
+	Faucet struct {
+		URL        string            `yaml:"url"`
+		WebsiteURL string            `yaml:"website_url"`
+		SiteKey    string            `yaml:"site_key"`
+		Headers    map[string]string `yaml:"headers"`
+	} `yaml:"faucet"`
+
+	Contracts struct {
+		Multicall3 string `yaml:"multicall3"`
+		Disperse   string `yaml:"disperse"`
+		// TransferNFT are the collections the transfer_nft module moves
+		TransferNFT []NFTContractConfig `yaml:"transfer_nft"`
+		// BalanceNFTs and Tokens are the ERC-721 and ERC-20 columns of the balances report
+		BalanceNFTs []string `yaml:"balance_nfts"`
+		Tokens      []string `yaml:"tokens"`
+	} `yaml:"contracts"`
+
+	Drops []DropConfig `yaml:"drops"`
+}
+
+// NFTContractConfig describes an NFT collection the account may hold.
+// ERC-1155 holdings are looked up for TokenIDs only, ERC-721 tokens are
+// enumerated or found in Transfer logs starting at FromBlock.
+type NFTContractConfig struct {
+	Address   string   `yaml:"address"`
+	Standard  string   `yaml:"standard"`
+	TokenIDs  []string `yaml:"token_ids"`
+	FromBlock uint64   `yaml:"from_block"`
+}
+
+// EtherRange is an inclusive range of ETH amounts written as decimal strings
+type EtherRange struct {
+	Min string `yaml:"min"`
+	Max string `yaml:"max"`
+}
+
+// Settings is config.yaml. Fields tagged secret accept "env:NAME" or "file:path"
+// instead of the value and are redacted when the config is printed.
 type Settings struct {
+	Network  string                   `yaml:"network"`
+	Networks map[string]NetworkConfig `yaml:"networks"`
+	// RPCURL replaces rpc endpoints of the selected network, comma-separated
+	RPCURL string `yaml:"rpc_url"`
+
+	Paths struct {
+		PrivateKeys string `yaml:"private_keys"`
+		Accounts    string `yaml:"accounts"`
+		Proxies     string `yaml:"proxies"`
+	} `yaml:"paths"`
+
 	DelayBeforeStart struct {
 		Min int `yaml:"min"`
 		Max int `yaml:"max"`
@@ -35,6 +124,66 @@ type Settings struct {
 		Max int `yaml:"max"`
 	} `yaml:"delay_between_accs"`
 
-	CapmonsterAPIKey string `yaml:"capmonster_api_key"`
+	RPC struct {
+		RequestsPerSecond int `yaml:"requests_per_second"`
+		RetryAttempts     int `yaml:"retry_attempts"`
+		RetryDelay        int `yaml:"retry_delay"`
+	} `yaml:"rpc"`
+
+	ProxyCheck struct {
+		URL      string `yaml:"url"`
+		Interval int    `yaml:"interval"`
+		Timeout  int    `yaml:"timeout"`
+	} `yaml:"proxy_check"`
+
+	Signer struct {
+		Type      string `yaml:"type"`
+		URL       string `yaml:"url"`
+		AuthToken string `yaml:"auth_token" secret:"true"`
+	} `yaml:"signer"`
+
+	// Drops are used when the active network defines none
+	Drops []DropConfig `yaml:"drops"`
+
+	Fund struct {
+		FunderKey     string     `yaml:"funder_key" secret:"true"`
+		TargetBalance string     `yaml:"target_balance"`
+		Amount        EtherRange `yaml:"amount"`
+		Threshold     string     `yaml:"threshold"`
+	} `yaml:"fund"`
+
+	Sweep struct {
+		To         string `yaml:"to"`
+		MinBalance string `yaml:"min_balance"`
+	} `yaml:"sweep"`
+
+	TransferNFT struct {
+		To string `yaml:"to"`
+	} `yaml:"transfer_nft"`
+
+	Balances struct {
+		CSVPath string `yaml:"csv_path"`
+	} `yaml:"balances"`
+
+	Faucet struct {
+		PayoutTimeout  int `yaml:"payout_timeout"`
+		Cooldown       int `yaml:"cooldown"`
+		RequestTimeout int `yaml:"request_timeout"`
+	} `yaml:"faucet"`
+
+	Metrics struct {
+		// Listen is the address of the Prometheus /metrics endpoint, e.g. ":9090"; empty disables it
+		Listen string `yaml:"listen"`
+	} `yaml:"metrics"`
+
+	// StatePath is the JSON file keeping faucet claims and other per-account state between runs
+	StatePath string `yaml:"state_path"`
+
+	// KeystorePassword decrypts keystore files of accounts.yaml; asked at start when empty
+	KeystorePassword string `yaml:"keystore_password" secret:"true"`
+
+	CapmonsterAPIKey string `yaml:"capmonster_api_key" secret:"true"`
+	// CapmonsterURL is the base URL of the Capmonster API
+	CapmonsterURL string `yaml:"capmonster_url"`
 	ShuffleAccs bool `yaml:"shuffle_accs"`
 }
@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/manifoldco/promptui"
+	"gopkg.in/yaml.v3"
+
+	"main/pkg/global"
+	"main/pkg/types"
+)
+
+// AccountEntry is one account of accounts.yaml
+type AccountEntry struct {
+	// Key is a private key, or an address with a remote signer; "env:NAME" and
+	// "file:path" read it from an environment variable or a file
+	Key string `yaml:"key"`
+	// Keystore is the path of an encrypted keystore file used instead of Key
+	Keystore string `yaml:"keystore"`
+
+	Proxy string `yaml:"proxy"`
+	Label string `yaml:"label"`
+	Group string `yaml:"group"`
+
+	MaxValue    string   `yaml:"max_value"`
+	SkipModules []string `yaml:"skip_modules"`
+}
+
+var (
+	keystorePassword     string
+	keystorePasswordErr  error
+	keystorePasswordOnce sync.Once
+)
+
+// getKeystorePassword returns keystore_password of config.yaml or asks for it once
+func getKeystorePassword() (string, error) {
+	keystorePasswordOnce.Do(func() {
+		keystorePassword = global.Config.KeystorePassword
+		if keystorePassword != "" {
+			return
+		}
+
+		prompt := promptui.Prompt{Label: "Keystore password", Mask: '*'}
+		keystorePassword, keystorePasswordErr = prompt.Run()
+	})
+
+	return keystorePassword, keystorePasswordErr
+}
+
+// LoadAccountsFile reads accounts with their proxies, labels and overrides from a YAML list
+func LoadAccountsFile(path string) ([]types.AccountData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []AccountEntry
+	if err = yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+
+	accounts := make([]types.AccountData, 0, len(entries))
+	for i, entry := range entries {
+		account, err := entry.account()
+		if err != nil {
+			return nil, fmt.Errorf("%s: account %d: %w", path, i+1, err)
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+func (e AccountEntry) account() (types.AccountData, error) {
+	var account types.AccountData
+
+	switch {
+	case e.Key != "" && e.Keystore != "":
+		return account, errors.New("key and keystore are both set")
+	case e.Key != "":
+		key, err := ResolveSecret(e.Key)
+		if err != nil {
+			return account, err
+		}
+
+		if account, err = ParseAccountKey(key); err != nil {
+			return account, err
+		}
+	case e.Keystore != "":
+		keyJSON, err := os.ReadFile(e.Keystore)
+		if err != nil {
+			return account, err
+		}
+
+		password, err := getKeystorePassword()
+		if err != nil {
+			return account, err
+		}
+
+		key, err := keystore.DecryptKey(keyJSON, password)
+		if err != nil {
+			return account, fmt.Errorf("error decrypting %s: %w", e.Keystore, err)
+		}
+
+		account = types.AccountData{
+			AccountLogData: key.Address.Hex(),
+			AccountKey:     key.PrivateKey,
+			AccountAddress: key.Address,
+		}
+	default:
+		return account, errors.New("key or keystore is required")
+	}
+
+	if e.Proxy != "" {
+		proxy, err := parseProxy(e.Proxy)
+		if err != nil {
+			return account, err
+		}
+		account.Proxy = proxy
+	}
+
+	if e.MaxValue != "" {
+		maxValue, err := ParseEther(e.MaxValue)
+		if err != nil {
+			return account, fmt.Errorf("invalid max_value: %w", err)
+		}
+		account.MaxValue = maxValue
+	}
+
+	account.Label = e.Label
+	account.Group = e.Group
+	account.SkipModules = e.SkipModules
+
+	return account, nil
+}
+
+// CheckKeysFile reports lines of private_keys.txt that are neither a private key nor
+// an address, without echoing the lines themselves
+func CheckKeysFile(path string) (int, []error) {
+	rows, err := ReadFileByRows(path)
+	if err != nil {
+		return 0, []error{err}
+	}
+
+	var valid int
+	var problems []error
+	for i, row := range rows {
+		input := RemoveHexPrefix(strings.TrimSpace(row))
+		if input == "" {
+			continue
+		}
+
+		if _, err = crypto.HexToECDSA(input); err != nil && !common.IsHexAddress("0x"+input) {
+			problems = append(problems, fmt.Errorf("%s: line %d is neither a private key nor an address", path, i+1))
+			continue
+		}
+		valid++
+	}
+
+	return valid, problems
+}
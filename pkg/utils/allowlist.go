@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// AllowlistEntry is a single allowlist row. Empty or "unlimited" quantity and
+// price are stored as max uint256, an empty currency as the zero address,
+// matching the defaults thirdweb hashes allowlists with.
+type AllowlistEntry struct {
+	Address      common.Address
+	MaxClaimable *big.Int
+	Price        *big.Int
+	Currency     common.Address
+}
+
+// Leaf is keccak256(abi.encodePacked(address, maxClaimable, price, currency))
+func (e AllowlistEntry) Leaf() common.Hash {
+	return crypto.Keccak256Hash(
+		e.Address.Bytes(),
+		common.LeftPadBytes(e.MaxClaimable.Bytes(), 32),
+		common.LeftPadBytes(e.Price.Bytes(), 32),
+		e.Currency.Bytes(),
+	)
+}
+
+type Allowlist struct {
+	Entries map[common.Address]AllowlistEntry
+	Tree    *MerkleTree
+}
+
+// Proof returns the account's allowlist entry and its Merkle proof
+func (a *Allowlist) Proof(address common.Address) (AllowlistEntry, []common.Hash, bool) {
+	entry, ok := a.Entries[address]
+	if !ok {
+		return AllowlistEntry{}, nil, false
+	}
+
+	proof, ok := a.Tree.Proof(entry.Leaf())
+	return entry, proof, ok
+}
+
+// loadedAllowlist is a cached outcome of reading an allowlist, failures included
+type loadedAllowlist struct {
+	allowlist *Allowlist
+	err       error
+}
+
+var (
+	allowlistCache = make(map[string]loadedAllowlist)
+	allowlistMutex sync.Mutex
+)
+
+func parseAllowlistNumber(value string) (*big.Int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "unlimited") {
+		return new(big.Int).Set(math.MaxBig256), nil
+	}
+
+	number, ok := new(big.Int).SetString(value, 10)
+	if !ok || number.Sign() < 0 {
+		return nil, fmt.Errorf("invalid number %q", value)
+	}
+	return number, nil
+}
+
+// LoadAllowlist reads a CSV with address, maxClaimable, price (wei) and currency
+// columns and builds its Merkle tree. Only the address column is mandatory,
+// a header row is skipped. Every path is read once per run, a failure is
+// reported again to later accounts of the drop without re-reading the file.
+func LoadAllowlist(path string) (*Allowlist, error) {
+	allowlistMutex.Lock()
+	defer allowlistMutex.Unlock()
+
+	loaded, ok := allowlistCache[path]
+	if !ok {
+		loaded.allowlist, loaded.err = readAllowlist(path)
+		allowlistCache[path] = loaded
+	}
+
+	return loaded.allowlist, loaded.err
+}
+
+func readAllowlist(path string) (*Allowlist, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	allowlist := &Allowlist{Entries: make(map[common.Address]AllowlistEntry)}
+	leaves := make([]common.Hash, 0, len(records))
+
+	for i, record := range records {
+		for len(record) < 4 {
+			record = append(record, "")
+		}
+
+		address := strings.TrimSpace(record[0])
+		if address == "" {
+			continue
+		}
+
+		if !common.IsHexAddress(address) {
+			if i == 0 {
+				continue // header
+			}
+			return nil, fmt.Errorf("%s:%d: invalid address %q", path, i+1, address)
+		}
+
+		entry := AllowlistEntry{Address: common.HexToAddress(address)}
+
+		if entry.MaxClaimable, err = parseAllowlistNumber(record[1]); err != nil {
+			return nil, fmt.Errorf("%s:%d: maxClaimable: %w", path, i+1, err)
+		}
+
+		if entry.Price, err = parseAllowlistNumber(record[2]); err != nil {
+			return nil, fmt.Errorf("%s:%d: price: %w", path, i+1, err)
+		}
+
+		if currency := strings.TrimSpace(record[3]); currency != "" {
+			if !common.IsHexAddress(currency) {
+				return nil, fmt.Errorf("%s:%d: invalid currency %q", path, i+1, currency)
+			}
+			entry.Currency = common.HexToAddress(currency)
+		}
+
+		if _, ok := allowlist.Entries[entry.Address]; ok {
+			return nil, fmt.Errorf("%s:%d: duplicate address %s", path, i+1, entry.Address)
+		}
+
+		allowlist.Entries[entry.Address] = entry
+		leaves = append(leaves, entry.Leaf())
+	}
+
+	allowlist.Tree = NewMerkleTree(leaves)
+
+	return allowlist, nil
+}
@@ -10,19 +10,21 @@ import (
 )
 
 func Cli() {
+	items := []string{
+		"Faucet test tokens",
+		"Fund wallets",
+		"Sweep ETH to master wallet",
+		"Transfer NFTs to master wallet",
+	}
+
+	for _, drop := range global.Network.Drops {
+		items = append(items, drop.Name)
+	}
+
 	prompt := promptui.Select{
 		Label: "Select module",
-		Items: []string{
-			"Faucet test tokens",
-			"Mint FUN Starts NFT",
-			"Mint Megamafia NFT",
-			"Mint Mega Cat NFT",
-			"Mint Blackhole NFT",
-			"Mint Xyroph NFT",
-			"Mint Lord Lapin NFT",
-			"Mint Angry Monkeys",
-			"Mint Bloom NFT",
-		},
+		Items: items,
+		Size:  len(items),
 	}
 
 	_, result, err := prompt.Run()
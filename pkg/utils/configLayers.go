@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"main/pkg/types"
+)
+
+// EnvPrefix starts environment variables overriding config.yaml, e.g.
+// MEGAETH_RPC_URL for rpc_url or MEGAETH_FAUCET_COOLDOWN for faucet.cooldown
+const EnvPrefix = "MEGAETH_"
+
+const redacted = "<redacted>"
+
+// configField is a scalar field of Settings with its dotted yaml key
+type configField struct {
+	key    string
+	value  reflect.Value
+	secret bool
+}
+
+// configFields lists every string, bool, integer and string list field of config.
+// Fields inside lists of structs (drops, contracts) are not included. Map values
+// are copied out and have to be stored back with the returned commit function.
+func configFields(config *types.Settings) ([]configField, func()) {
+	var fields []configField
+	var commits []func()
+
+	var walk func(value reflect.Value, key string, secret bool)
+	walk = func(value reflect.Value, key string, secret bool) {
+		switch value.Kind() {
+		case reflect.Struct:
+			for i := 0; i < value.NumField(); i++ {
+				structField := value.Type().Field(i)
+				name := strings.Split(structField.Tag.Get("yaml"), ",")[0]
+				if name == "" || name == "-" {
+					continue
+				}
+				if key != "" {
+					name = key + "." + name
+				}
+				walk(value.Field(i), name, structField.Tag.Get("secret") == "true")
+			}
+		case reflect.Map:
+			if value.Type().Elem().Kind() != reflect.Struct {
+				return
+			}
+			for _, mapKey := range value.MapKeys() {
+				elem := reflect.New(value.Type().Elem()).Elem()
+				elem.Set(value.MapIndex(mapKey))
+				walk(elem, key+"."+mapKey.String(), false)
+
+				mapValue, mapKey := value, mapKey
+				commits = append(commits, func() { mapValue.SetMapIndex(mapKey, elem) })
+			}
+		case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Uint64:
+			fields = append(fields, configField{key: key, value: value, secret: secret})
+		case reflect.Slice:
+			if value.Type().Elem().Kind() == reflect.String {
+				fields = append(fields, configField{key: key, value: value, secret: secret})
+			}
+		}
+	}
+
+	walk(reflect.ValueOf(config).Elem(), "", false)
+
+	return fields, func() {
+		for _, commit := range commits {
+			commit()
+		}
+	}
+}
+
+// set parses text into the field; string lists are comma-separated
+func (f configField) set(text string) error {
+	switch f.value.Kind() {
+	case reflect.String:
+		f.value.SetString(text)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not true or false", f.key, text)
+		}
+		f.value.SetBool(value)
+	case reflect.Int, reflect.Int64:
+		value, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a number", f.key, text)
+		}
+		f.value.SetInt(value)
+	case reflect.Uint64:
+		value, err := strconv.ParseUint(text, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a number", f.key, text)
+		}
+		f.value.SetUint(value)
+	case reflect.Slice:
+		f.value.Set(reflect.ValueOf(SplitList(text)))
+	}
+	return nil
+}
+
+// SplitList splits a comma-separated list dropping blank items
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// envName is the environment variable of a dotted config key
+func envName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// applyOverrides sets fields from MEGAETH_* environment variables, then from
+// "key=value" overrides given on the command line
+func applyOverrides(config *types.Settings, overrides []string) error {
+	fields, commit := configFields(config)
+	defer commit()
+
+	var problems []error
+
+	for _, field := range fields {
+		if text, ok := os.LookupEnv(envName(field.key)); ok {
+			if err := field.set(text); err != nil {
+				problems = append(problems, fmt.Errorf("%s: %w", envName(field.key), err))
+			}
+		}
+	}
+
+	for _, override := range overrides {
+		key, text, ok := strings.Cut(override, "=")
+		if !ok {
+			problems = append(problems, fmt.Errorf("-set %s: expected key=value", override))
+			continue
+		}
+
+		found := false
+		for _, field := range fields {
+			if field.key == key {
+				found = true
+				if err := field.set(text); err != nil {
+					problems = append(problems, fmt.Errorf("-set: %w", err))
+				}
+			}
+		}
+		if !found {
+			problems = append(problems, fmt.Errorf("-set %s: unknown key %s", override, key))
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+// ResolveSecret returns the value of "env:NAME" or "file:path" references and
+// any other value unchanged, so secrets can stay out of config.yaml
+func ResolveSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, "env:"):
+		name := strings.TrimPrefix(value, "env:")
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return secret, nil
+	case strings.HasPrefix(value, "file:"):
+		data, err := os.ReadFile(strings.TrimPrefix(value, "file:"))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return value, nil
+}
+
+// resolveSecrets replaces env: and file: references of secret fields with their values
+func resolveSecrets(config *types.Settings) error {
+	fields, commit := configFields(config)
+	defer commit()
+
+	var problems []error
+	for _, field := range fields {
+		if !field.secret || field.value.Kind() != reflect.String {
+			continue
+		}
+
+		secret, err := ResolveSecret(field.value.String())
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", field.key, err))
+			continue
+		}
+		field.value.SetString(secret)
+	}
+
+	return errors.Join(problems...)
+}
+
+// RedactedConfig returns config as YAML with every set secret replaced
+func RedactedConfig(config types.Settings) ([]byte, error) {
+	// networks are shared with config, copy the map before storing edited profiles back
+	networks := make(map[string]types.NetworkConfig, len(config.Networks))
+	for name, network := range config.Networks {
+		networks[name] = network
+	}
+	config.Networks = networks
+
+	fields, commit := configFields(&config)
+	for _, field := range fields {
+		if field.secret && field.value.Kind() == reflect.String && field.value.String() != "" {
+			field.value.SetString(redacted)
+		}
+	}
+	commit()
+
+	return yaml.Marshal(config)
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const etherDecimals = 18
+
+// ParseUnits converts a decimal amount like "0.015" into base units with given decimals
+func ParseUnits(amount string, decimals int) (*big.Int, error) {
+	amount = strings.TrimSpace(amount)
+	whole, fraction, _ := strings.Cut(amount, ".")
+
+	if len(fraction) > decimals {
+		return nil, fmt.Errorf("invalid amount %q: more than %d decimals", amount, decimals)
+	}
+
+	if whole == "" {
+		whole = "0"
+	}
+
+	value, ok := new(big.Int).SetString(whole+fraction+strings.Repeat("0", decimals-len(fraction)), 10)
+	if !ok || value.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+
+	return value, nil
+}
+
+// FormatUnits converts base units into a decimal string with given decimals
+func FormatUnits(value *big.Int, decimals int) string {
+	if value == nil {
+		return "0"
+	}
+
+	digits := new(big.Int).Abs(value).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	whole, fraction := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+
+	result := whole
+	if fraction != "" {
+		result += "." + fraction
+	}
+	if value.Sign() < 0 {
+		result = "-" + result
+	}
+
+	return result
+}
+
+// ParseEther converts an ETH amount into wei
+func ParseEther(amount string) (*big.Int, error) {
+	return ParseUnits(amount, etherDecimals)
+}
+
+// FormatEther converts wei into an ETH amount
+func FormatEther(value *big.Int) string {
+	return FormatUnits(value, etherDecimals)
+}
+
+// RandomBigInt returns a random value in [min, max]
+func RandomBigInt(min *big.Int, max *big.Int) *big.Int {
+	if max.Cmp(min) <= 0 {
+		return new(big.Int).Set(min)
+	}
+
+	span := new(big.Int).Sub(max, min)
+	span.Add(span, big.NewInt(1))
+
+	offset, err := rand.Int(rand.Reader, span)
+	if err != nil {
+		return new(big.Int).Set(min)
+	}
+
+	return offset.Add(offset, min)
+}
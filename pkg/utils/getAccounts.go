@@ -2,13 +2,13 @@ package utils
 
 import (
 	"crypto/ecdsa"
-	"math/rand"
+	"errors"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	
-	"main/pkg/global"
 	"main/pkg/types"
 )
 
@@ -59,10 +59,28 @@ func GetAccounts(inputs []string, onlyKeys bool) ([]types.AccountData, error) {
 		})
 	}
 
-	shuffleFlag := global.Config.ShuffleAccs
-	if shuffleFlag {
-		rand.Shuffle(len(accounts), func(i, j int) { accounts[i], accounts[j] = accounts[j], accounts[i] })
+	return accounts, nil
+}
+
+// ParseAccountKey parses a single private key, or an address used with a remote
+// signer. Unlike GetAccounts it never logs the input, so it suits secrets.
+func ParseAccountKey(key string) (types.AccountData, error) {
+	key = RemoveHexPrefix(strings.TrimSpace(key))
+
+	if common.IsHexAddress("0x" + key) {
+		address := common.HexToAddress("0x" + key)
+		return types.AccountData{AccountLogData: address.Hex(), AccountAddress: address}, nil
 	}
 
-	return accounts, nil
+	privateKey, err := crypto.HexToECDSA(key)
+	if err != nil {
+		return types.AccountData{}, errors.New("key is neither a private key nor an address")
+	}
+
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	return types.AccountData{
+		AccountLogData: address.Hex(),
+		AccountKey:     privateKey,
+		AccountAddress: address,
+	}, nil
 }
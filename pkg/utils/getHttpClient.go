@@ -3,17 +3,14 @@ package utils
 import (
 	"net/url"
 	"time"
-	"sync"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/valyala/fasthttp"
 	"github.com/valyala/fasthttp/fasthttpproxy"
 
-	"main/pkg/global"
+	"main/pkg/types"
 )
 
-var clientIndex int
-
 func CreateClient(proxy string) *fasthttp.Client {
 	var dial fasthttp.DialFunc
 
@@ -44,17 +41,24 @@ func CreateClient(proxy string) *fasthttp.Client {
 	return client
 }
 
+// GetClient returns the next healthy proxy client of Pool
 func GetClient() *fasthttp.Client {
-	mutex := &sync.Mutex{}
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	if len(global.Clients) == 0 {
+	if Pool == nil {
 		return nil
 	}
 
-	client := global.Clients[clientIndex]
-	clientIndex = (clientIndex + 1) % len(global.Clients)
+	return Pool.Next()
+}
 
-	return client
+// GetAccountClient returns the client of the proxy account sticks to. It fails
+// instead of sending the requests of the account through another proxy.
+func GetAccountClient(account types.AccountData) (*fasthttp.Client, error) {
+	if Pool == nil {
+		if account.Proxy != "" {
+			return CreateClient(account.Proxy), nil
+		}
+		return nil, nil
+	}
+
+	return Pool.ForAccount(account)
 }
@@ -110,3 +110,27 @@ func GetProxy() string {
 	}
 	return Proxies[rand.Intn(len(Proxies))]
 }
+
+// CheckProxiesFile reports lines of proxies.txt in none of the supported formats
+func CheckProxiesFile(path string) (int, []error) {
+	rows, err := ReadFileByRows(path)
+	if err != nil {
+		return 0, []error{err}
+	}
+
+	var valid int
+	var problems []error
+	for i, row := range rows {
+		if strings.TrimSpace(row) == "" {
+			continue
+		}
+
+		if _, err = parseProxy(row); err != nil {
+			problems = append(problems, fmt.Errorf("%s: line %d is not a proxy", path, i+1))
+			continue
+		}
+		valid++
+	}
+
+	return valid, problems
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// JSONResponse is the status and raw body of a JSON request
+type JSONResponse struct {
+	StatusCode int
+	Body       []byte
+}
+
+// PostJSON sends body as JSON to url through client. The request is bounded by
+// timeout and the deadline of ctx, whichever comes first. The body is copied
+// before the pooled fasthttp objects are released.
+func PostJSON(
+	ctx context.Context,
+	client *fasthttp.Client,
+	url string,
+	headers map[string]string,
+	timeout time.Duration,
+	body interface{},
+) (*JSONResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(url)
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.Header.SetContentType("application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	req.SetBody(payload)
+
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	if err = client.DoDeadline(req, resp, deadline); err != nil {
+		return nil, err
+	}
+
+	return &JSONResponse{
+		StatusCode: resp.StatusCode(),
+		Body:       append([]byte(nil), resp.Body()...),
+	}, nil
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MerkleTree is a keccak256 tree with sorted leaves and sorted pairs, the layout
+// thirdweb builds allowlists with (merkletreejs with sort enabled). A node
+// without a sibling is promoted to the next layer unchanged.
+type MerkleTree struct {
+	layers [][]common.Hash
+}
+
+func NewMerkleTree(leaves []common.Hash) *MerkleTree {
+	layer := make([]common.Hash, len(leaves))
+	copy(layer, leaves)
+	sort.Slice(layer, func(i, j int) bool {
+		return bytes.Compare(layer[i][:], layer[j][:]) < 0
+	})
+
+	tree := &MerkleTree{layers: [][]common.Hash{layer}}
+
+	for len(layer) > 1 {
+		next := make([]common.Hash, 0, (len(layer)+1)/2)
+		for i := 0; i < len(layer); i += 2 {
+			if i+1 == len(layer) {
+				next = append(next, layer[i])
+				continue
+			}
+			next = append(next, hashPair(layer[i], layer[i+1]))
+		}
+		tree.layers = append(tree.layers, next)
+		layer = next
+	}
+
+	return tree
+}
+
+func hashPair(a common.Hash, b common.Hash) common.Hash {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256Hash(a[:], b[:])
+}
+
+// Root returns the tree root, zero hash for an empty tree
+func (t *MerkleTree) Root() common.Hash {
+	top := t.layers[len(t.layers)-1]
+	if len(top) == 0 {
+		return common.Hash{}
+	}
+	return top[0]
+}
+
+// Proof returns sibling hashes from leaf up to the root, false if leaf is not in the tree
+func (t *MerkleTree) Proof(leaf common.Hash) ([]common.Hash, bool) {
+	index := -1
+	for i, node := range t.layers[0] {
+		if node == leaf {
+			index = i
+			break
+		}
+	}
+
+	if index < 0 {
+		return nil, false
+	}
+
+	proof := []common.Hash{}
+	for _, layer := range t.layers[:len(t.layers)-1] {
+		sibling := index ^ 1
+		if sibling < len(layer) {
+			proof = append(proof, layer[sibling])
+		}
+		index /= 2
+	}
+
+	return proof, true
+}
+
+// VerifyProof recomputes the root from leaf and proof
+func VerifyProof(root common.Hash, leaf common.Hash, proof []common.Hash) bool {
+	node := leaf
+	for _, sibling := range proof {
+		node = hashPair(node, sibling)
+	}
+	return node == root
+}
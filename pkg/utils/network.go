@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+
+	"main/pkg/global"
+	"main/pkg/types"
+)
+
+// DefaultNetwork is the profile used when config.yaml defines no networks
+const DefaultNetwork = "carrot"
+
+func carrotNetwork() types.NetworkConfig {
+	network := types.NetworkConfig{
+		RPC:      []string{"https://carrot.megaeth.com/rpc"},
+		WSURL:    "",
+		ChainID:  6342,
+		Explorer: "https://www.megaexplorer.xyz",
+	}
+	network.Faucet.URL = "https://carrot.megaeth.com/claim"
+	network.Faucet.WebsiteURL = "https://testnet.megaeth.com/#1"
+	network.Faucet.SiteKey = "0x4AAAAAABA4JXCaw9E2Py-9"
+
+	return network
+}
+
+// SelectNetwork resolves the network profile of the run into global.Network:
+// the one named by "network" in config.yaml, the only defined one, or a menu choice
+func SelectNetwork() error {
+	networks := global.Config.Networks
+
+	name := global.Config.Network
+
+	if name == "" {
+		names := make([]string, 0, len(networks))
+		for networkName := range networks {
+			names = append(names, networkName)
+		}
+		sort.Strings(names)
+
+		name = names[0]
+
+		if len(names) > 1 {
+			prompt := promptui.Select{
+				Label: "Select network",
+				Items: names,
+				Size:  len(names),
+			}
+
+			var err error
+			if _, name, err = prompt.Run(); err != nil {
+				return fmt.Errorf("network selection: %w", err)
+			}
+		}
+	}
+
+	network, ok := networks[name]
+	if !ok {
+		return fmt.Errorf("network %q is not defined in networks", name)
+	}
+
+	if rpcURL := SplitList(global.Config.RPCURL); len(rpcURL) > 0 {
+		network.RPC = rpcURL
+	}
+
+	if len(network.RPC) == 0 {
+		return fmt.Errorf("network %q has no rpc endpoints", name)
+	}
+
+	if len(network.Drops) == 0 {
+		network.Drops = global.Config.Drops
+	}
+
+	global.Network = &network
+	global.NetworkName = name
+
+	return nil
+}
+
+// ExplorerTx returns the explorer link of a transaction, or the bare hash
+// when the active network has no explorer
+func ExplorerTx(txHash string) string {
+	if global.Network == nil || global.Network.Explorer == "" {
+		return txHash
+	}
+	return strings.TrimRight(global.Network.Explorer, "/") + "/tx/" + txHash
+}
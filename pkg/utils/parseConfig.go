@@ -1,8 +1,14 @@
 package utils
 
 import (
-	"gopkg.in/yaml.v3"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
 
 	log "github.com/sirupsen/logrus"
 
@@ -10,20 +16,54 @@ import (
 	"main/pkg/types"
 )
 
-func ParseConfig(configPath string) {
+// unknownFieldRegexp matches yaml.v3 reports of keys missing from types.Settings
+var unknownFieldRegexp = regexp.MustCompile(`field (\S+) not found in type .*`)
+
+// LoadConfig decodes config.yaml over DefaultConfig rejecting unknown keys, adds the
+// default network when none is defined, applies MEGAETH_* environment variables and
+// "key=value" overrides on top, resolves secret references and validates the result
+func LoadConfig(configPath string, overrides []string) (*types.Settings, error) {
 	yamlFile, err := os.ReadFile(configPath)
 
 	if err != nil {
-		log.Fatalf("Problem with reading config.yaml file: %v\n", err)
+		return nil, fmt.Errorf("problem with reading %s: %w", configPath, err)
+	}
+
+	config := DefaultConfig()
+
+	decoder := yaml.NewDecoder(bytes.NewReader(yamlFile))
+	decoder.KnownFields(true)
+
+	if err = decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("problem with parsing %s:\n%s", configPath,
+			unknownFieldRegexp.ReplaceAllString(err.Error(), "unknown key $1"),
+		)
 	}
 
-	var config types.Settings
+	// the default profile exists before the env and -set layers, so they can override it too
+	defaultNetworks(&config)
+
+	if err = applyOverrides(&config, overrides); err != nil {
+		return nil, err
+	}
+
+	if err = resolveSecrets(&config); err != nil {
+		return nil, err
+	}
+
+	if err = ValidateConfig(&config); err != nil {
+		return nil, fmt.Errorf("invalid %s:\n%w", configPath, err)
+	}
+
+	return &config, nil
+}
 
-	err = yaml.Unmarshal(yamlFile, &config)
+func ParseConfig(configPath string, overrides []string) {
+	config, err := LoadConfig(configPath, overrides)
 
 	if err != nil {
-		log.Fatalf("Problem with unmarshaling YAML: %v\n", err)
+		log.Fatalf("%v\n", err)
 	}
 
-	global.Config = &config
+	global.Config = config
 }
@@ -0,0 +1,381 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	log "github.com/sirupsen/logrus"
+	"github.com/valyala/fasthttp"
+
+	"main/pkg/global"
+	"main/pkg/state"
+	"main/pkg/types"
+)
+
+const (
+	// DefaultProxyCheckURL answers with the caller's IP, which shows the exit address of every proxy
+	DefaultProxyCheckURL = "https://api.ipify.org"
+
+	// quarantineBase is the first re-check delay of a failing proxy, doubled on every failure
+	quarantineBase = 30 * time.Second
+	quarantineMax  = 10 * time.Minute
+	// recheckTick is how often due quarantined proxies are looked for
+	recheckTick = 5 * time.Second
+)
+
+// Pool is the proxy pool of the run, set at start by initProxies of main
+var Pool *ProxyPool
+
+type proxyEntry struct {
+	proxy  string
+	client *fasthttp.Client
+
+	healthy   bool
+	checked   bool
+	latency   time.Duration
+	exitIP    string
+	lastErr   error
+	failures  int
+	recheckAt time.Time
+}
+
+// ProxyPool hands out HTTP clients of healthy proxies. Failing proxies are
+// quarantined and re-checked with exponential backoff. Accounts stick to one
+// proxy, see ForAccount.
+type ProxyPool struct {
+	mutex   sync.Mutex
+	entries []*proxyEntry
+	// shared is the number of leading entries from proxies.txt, the rest are pinned by accounts
+	shared   int
+	next     int
+	assigned map[common.Address]*proxyEntry
+
+	checkURL     string
+	checkTimeout time.Duration
+}
+
+// NewProxyPool creates a client for every proxy, or a single direct client without proxies
+func NewProxyPool(proxies []string, checkURL string, checkTimeout time.Duration) *ProxyPool {
+	pool := &ProxyPool{
+		assigned:     make(map[common.Address]*proxyEntry),
+		checkURL:     checkURL,
+		checkTimeout: checkTimeout,
+	}
+
+	if len(proxies) == 0 {
+		proxies = []string{""}
+	}
+
+	for _, proxy := range proxies {
+		pool.entries = append(pool.entries, &proxyEntry{proxy: proxy, client: CreateClient(proxy), healthy: true})
+	}
+	pool.shared = len(pool.entries)
+
+	return pool
+}
+
+// proxyName hides credentials of proxy for logs
+func proxyName(proxy string) string {
+	if proxy == "" {
+		return "direct"
+	}
+	if parsed, err := url.Parse(proxy); err == nil && parsed.Host != "" {
+		return parsed.Scheme + "://" + parsed.Host
+	}
+	return proxy
+}
+
+// check requests checkURL through the proxy and records the outcome
+func (p *ProxyPool) check(ctx context.Context, entry *proxyEntry) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(p.checkURL)
+	req.Header.SetMethod(fasthttp.MethodGet)
+
+	deadline := time.Now().Add(p.checkTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	start := time.Now()
+	err := entry.client.DoDeadline(req, resp, deadline)
+	latency := time.Since(start)
+
+	if err == nil && resp.StatusCode() >= fasthttp.StatusInternalServerError {
+		err = fmt.Errorf("status %d", resp.StatusCode())
+	}
+
+	var exitIP string
+	if err == nil {
+		exitIP = strings.TrimSpace(string(resp.Body()))
+		if len(exitIP) > 45 {
+			exitIP = ""
+		}
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	entry.checked = true
+
+	if err != nil {
+		p.quarantine(entry, err)
+		return
+	}
+
+	if !entry.healthy {
+		log.Infof("[ProxyPool] | %s | Healthy again (%v)\n", proxyName(entry.proxy), latency.Round(time.Millisecond))
+	}
+
+	entry.healthy = true
+	entry.latency = latency
+	entry.exitIP = exitIP
+	entry.lastErr = nil
+	entry.failures = 0
+}
+
+// quarantine takes entry out of rotation until its next re-check. p.mutex must be held.
+func (p *ProxyPool) quarantine(entry *proxyEntry, err error) {
+	entry.failures++
+
+	backoff := quarantineBase << min(entry.failures-1, 10)
+	if backoff > quarantineMax {
+		backoff = quarantineMax
+	}
+
+	if entry.healthy {
+		log.Warnf("[ProxyPool] | %s | Quarantined for %v: %v\n", proxyName(entry.proxy), backoff, err)
+	}
+
+	entry.healthy = false
+	entry.lastErr = err
+	entry.recheckAt = time.Now().Add(backoff)
+}
+
+// CheckAll checks every proxy concurrently
+func (p *ProxyPool) CheckAll(ctx context.Context) {
+	p.mutex.Lock()
+	entries := append([]*proxyEntry(nil), p.entries...)
+	p.mutex.Unlock()
+
+	p.checkEntries(ctx, entries)
+}
+
+func (p *ProxyPool) checkEntries(ctx context.Context, entries []*proxyEntry) {
+	wg := &sync.WaitGroup{}
+	for _, entry := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.check(ctx, entry)
+		}()
+	}
+	wg.Wait()
+}
+
+// StartHealthChecks re-checks healthy proxies every interval and quarantined
+// ones once their backoff passed, until ctx is done
+func (p *ProxyPool) StartHealthChecks(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(recheckTick)
+		defer ticker.Stop()
+
+		lastFull := time.Now()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			full := interval > 0 && time.Since(lastFull) >= interval
+			if full {
+				lastFull = time.Now()
+			}
+
+			var due []*proxyEntry
+			p.mutex.Lock()
+			for _, entry := range p.entries {
+				if full || (!entry.healthy && time.Now().After(entry.recheckAt)) {
+					due = append(due, entry)
+				}
+			}
+			p.mutex.Unlock()
+
+			p.checkEntries(ctx, due)
+		}
+	}()
+}
+
+// MarkFailed quarantines the proxy of client after a failed request
+func (p *ProxyPool) MarkFailed(client *fasthttp.Client, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, entry := range p.entries {
+		if entry.client == client && entry.healthy && entry.proxy != "" {
+			p.quarantine(entry, err)
+		}
+	}
+}
+
+// Next returns the next healthy client in round-robin order. When every
+// proxy is quarantined the least recently failed one is still used.
+func (p *ProxyPool) Next() *fasthttp.Client {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.nextEntry().client
+}
+
+// nextEntry is Next with p.mutex held. Proxies pinned by accounts are not shared.
+func (p *ProxyPool) nextEntry() *proxyEntry {
+	shared := p.entries[:p.shared]
+
+	for range shared {
+		entry := shared[p.next]
+		p.next = (p.next + 1) % len(shared)
+		if entry.healthy {
+			return entry
+		}
+	}
+
+	fallback := shared[0]
+	for _, entry := range shared[1:] {
+		if entry.recheckAt.Before(fallback.recheckAt) {
+			fallback = entry
+		}
+	}
+	return fallback
+}
+
+// ForAccount returns the client of the proxy assigned to account, so every
+// request of one wallet leaves from the same IP. The proxy is, in order: the
+// one pinned in account.Proxy, the one saved in the state file by a previous
+// run, or one picked by hash of the address. The assignment is saved to the
+// state file. Another proxy is never substituted: while the assigned proxy is
+// quarantined an error is returned.
+func (p *ProxyPool) ForAccount(account types.AccountData) (*fasthttp.Client, error) {
+	address := account.AccountAddress
+
+	store, err := state.Default(global.Config.StatePath)
+	if err != nil {
+		log.Warnf("[%d/%d] | %s | [ProxyPool] | Problem with opening state file: %v\n",
+			global.CurrentProgress, global.TargetProgress, address, err,
+		)
+		store = nil
+	}
+
+	var saved string
+	if store != nil {
+		saved = store.Get(address).Proxy
+	}
+
+	p.mutex.Lock()
+
+	entry, ok := p.assigned[address]
+	if !ok {
+		entry = p.assign(account, saved)
+		p.assigned[address] = entry
+	}
+
+	healthy, recheckAt := entry.healthy, entry.recheckAt
+
+	p.mutex.Unlock()
+
+	if store != nil && entry.proxy != "" && entry.proxy != saved {
+		err = store.Update(address, func(account *state.Account) {
+			account.Proxy = entry.proxy
+		})
+		if err != nil {
+			log.Warnf("[%d/%d] | %s | [ProxyPool] | Problem with saving state file: %v\n",
+				global.CurrentProgress, global.TargetProgress, address, err,
+			)
+		}
+	}
+
+	if !healthy && entry.proxy != "" {
+		return nil, fmt.Errorf("proxy %s of the account is quarantined, next check at %s",
+			proxyName(entry.proxy), recheckAt.Format(time.DateTime),
+		)
+	}
+
+	return entry.client, nil
+}
+
+// assign picks the proxy of account. p.mutex must be held.
+func (p *ProxyPool) assign(account types.AccountData, saved string) *proxyEntry {
+	if account.Proxy != "" {
+		if entry := p.find(account.Proxy); entry != nil {
+			return entry
+		}
+
+		// pinned proxies missing from proxies.txt join the pool to be health-checked too
+		entry := &proxyEntry{proxy: account.Proxy, client: CreateClient(account.Proxy), healthy: true}
+		p.entries = append(p.entries, entry)
+		return entry
+	}
+
+	if saved != "" {
+		if entry := p.find(saved); entry != nil {
+			return entry
+		}
+	}
+
+	hash := fnv.New32a()
+	hash.Write(account.AccountAddress.Bytes())
+	entry := p.entries[hash.Sum32()%uint32(p.shared)]
+
+	if entry.proxy != "" {
+		log.Infof("[%d/%d] | %s | [ProxyPool] | Assigned proxy %s\n",
+			global.CurrentProgress, global.TargetProgress, account.AccountAddress, proxyName(entry.proxy),
+		)
+	}
+
+	return entry
+}
+
+// find returns the entry of proxy. p.mutex must be held.
+func (p *ProxyPool) find(proxy string) *proxyEntry {
+	for _, entry := range p.entries {
+		if entry.proxy == proxy {
+			return entry
+		}
+	}
+	return nil
+}
+
+// LogSummary prints the state of every proxy and the healthy/dead/unchecked totals
+func (p *ProxyPool) LogSummary() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	healthy, unchecked := 0, 0
+	for _, entry := range p.entries {
+		switch {
+		case !entry.checked:
+			log.Infof("[ProxyPool] | %s | Not checked\n", proxyName(entry.proxy))
+			unchecked++
+		case entry.healthy:
+			log.Infof("[ProxyPool] | %s | OK | %v | Exit IP: %s\n",
+				proxyName(entry.proxy), entry.latency.Round(time.Millisecond), entry.exitIP,
+			)
+			healthy++
+		default:
+			log.Warnf("[ProxyPool] | %s | Dead | %v\n", proxyName(entry.proxy), entry.lastErr)
+		}
+	}
+
+	log.Printf("Proxies: %d healthy, %d dead, %d unchecked\n", healthy, len(p.entries)-healthy-unchecked, unchecked)
+}
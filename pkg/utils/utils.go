@@ -26,11 +26,19 @@ func Sleep(delayMin int, delayMax int) {
 	time.Sleep(time.Duration(delay) * time.Second)
 }
 
-// LoadABI loads ABI from defined filepath
+var (
+	abiCache = make(map[string]abi.ABI)
+	abiMutex sync.Mutex
+)
+
+// LoadABI loads ABI from defined filepath. Parsed ABIs are cached by path for the whole run
 func LoadABI(filepath string) (abi.ABI, error) {
-	mutex := &sync.Mutex{}
-	mutex.Lock()
-	defer mutex.Unlock()
+	abiMutex.Lock()
+	defer abiMutex.Unlock()
+
+	if contractABI, ok := abiCache[filepath]; ok {
+		return contractABI, nil
+	}
 
 	abiFile, err := os.ReadFile(filepath)
 
@@ -50,5 +58,7 @@ func LoadABI(filepath string) (abi.ABI, error) {
 		return abi.ABI{}, errors.New(msg)
 	}
 
+	abiCache[filepath] = contractABI
+
 	return contractABI, nil
 }
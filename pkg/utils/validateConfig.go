@@ -0,0 +1,313 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"main/pkg/state"
+	"main/pkg/types"
+)
+
+// Defaults of config.yaml fields missing from the file
+const (
+	DefaultBalancesCSV       = "balances.csv"
+	DefaultSignerType        = "local"
+	DefaultRetryAttempts     = 3
+	DefaultRetryDelay        = 1
+	DefaultProxyCheckTimeout = 10
+	DefaultPayoutTimeout     = 120
+	DefaultFaucetCooldown    = 24
+	DefaultFaucetTimeout     = 30
+	DefaultCapmonsterURL     = "https://api.capmonster.cloud"
+)
+
+// DefaultConfig returns the settings config.yaml is decoded into, so keys missing
+// from the file keep these values while keys set to 0 or "" stay as written
+func DefaultConfig() types.Settings {
+	var config types.Settings
+
+	config.Paths.PrivateKeys = filepath.Join("config", "private_keys.txt")
+	config.Paths.Accounts = filepath.Join("config", "accounts.yaml")
+	config.Paths.Proxies = filepath.Join("config", "proxies.txt")
+
+	config.RPC.RetryAttempts = DefaultRetryAttempts
+	config.RPC.RetryDelay = DefaultRetryDelay
+
+	config.ProxyCheck.URL = DefaultProxyCheckURL
+	config.ProxyCheck.Timeout = DefaultProxyCheckTimeout
+
+	config.Signer.Type = DefaultSignerType
+
+	config.Balances.CSVPath = DefaultBalancesCSV
+
+	config.Faucet.PayoutTimeout = DefaultPayoutTimeout
+	config.Faucet.Cooldown = DefaultFaucetCooldown
+	config.Faucet.RequestTimeout = DefaultFaucetTimeout
+
+	config.StatePath = state.DefaultPath
+
+	config.CapmonsterURL = DefaultCapmonsterURL
+
+	return config
+}
+
+// defaultNetworks adds the carrot profile when config.yaml defines no networks
+func defaultNetworks(config *types.Settings) {
+	if len(config.Networks) == 0 {
+		config.Networks = map[string]types.NetworkConfig{DefaultNetwork: carrotNetwork()}
+	}
+}
+
+// configProblems collects every problem of config.yaml to report them at once
+type configProblems []error
+
+func (p *configProblems) add(field string, format string, args ...interface{}) {
+	*p = append(*p, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+}
+
+func (p *configProblems) nonNegative(field string, value int) {
+	if value < 0 {
+		p.add(field, "must not be negative, got %d", value)
+	}
+}
+
+func (p *configProblems) required(field string, value string) {
+	if value == "" {
+		p.add(field, "is required")
+	}
+}
+
+func (p *configProblems) positive(field string, value int) {
+	if value <= 0 {
+		p.add(field, "must be positive, got %d", value)
+	}
+}
+
+func (p *configProblems) delay(field string, min int, max int) {
+	p.nonNegative(field+".min", min)
+	p.nonNegative(field+".max", max)
+	if max < min {
+		p.add(field, "max (%d) is less than min (%d)", max, min)
+	}
+}
+
+func (p *configProblems) address(field string, value string) {
+	if value != "" && !common.IsHexAddress(value) {
+		p.add(field, "%q is not an address", value)
+	}
+}
+
+func (p *configProblems) url(field string, value string, schemes ...string) {
+	if value == "" {
+		return
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Host == "" {
+		p.add(field, "%q is not a URL", value)
+		return
+	}
+
+	if !slices.Contains(schemes, parsed.Scheme) {
+		p.add(field, "%q must start with %s://", value, strings.Join(schemes, ":// or "))
+	}
+}
+
+func (p *configProblems) ether(field string, value string) *big.Int {
+	if value == "" {
+		return nil
+	}
+
+	amount, err := ParseEther(value)
+	if err != nil {
+		p.add(field, "%q is not an ETH amount", value)
+		return nil
+	}
+	return amount
+}
+
+func (p *configProblems) network(field string, network types.NetworkConfig) {
+	if len(network.RPC) == 0 {
+		p.add(field+".rpc", "at least one endpoint is required")
+	}
+	for i, endpoint := range network.RPC {
+		p.url(fmt.Sprintf("%s.rpc[%d]", field, i), endpoint, "http", "https", "ws", "wss")
+	}
+
+	p.url(field+".ws_url", network.WSURL, "ws", "wss")
+	if network.ChainID < 0 {
+		p.add(field+".chain_id", "must not be negative, got %d", network.ChainID)
+	}
+	p.url(field+".explorer", network.Explorer, "http", "https")
+
+	p.url(field+".faucet.url", network.Faucet.URL, "http", "https")
+	p.url(field+".faucet.website_url", network.Faucet.WebsiteURL, "http", "https")
+	if network.Faucet.URL != "" && (network.Faucet.WebsiteURL == "" || network.Faucet.SiteKey == "") {
+		p.add(field+".faucet", "website_url and site_key are required with url")
+	}
+
+	p.address(field+".contracts.multicall3", network.Contracts.Multicall3)
+	p.address(field+".contracts.disperse", network.Contracts.Disperse)
+	p.nftContracts(field+".contracts.transfer_nft", network.Contracts.TransferNFT)
+	for i, contract := range network.Contracts.BalanceNFTs {
+		p.address(fmt.Sprintf("%s.contracts.balance_nfts[%d]", field, i), contract)
+	}
+	for i, token := range network.Contracts.Tokens {
+		p.address(fmt.Sprintf("%s.contracts.tokens[%d]", field, i), token)
+	}
+
+	p.drops(field+".drops", network.Drops)
+}
+
+func (p *configProblems) nftContracts(field string, contracts []types.NFTContractConfig) {
+	for i, contract := range contracts {
+		contractField := fmt.Sprintf("%s[%d]", field, i)
+		if contract.Address == "" {
+			p.add(contractField+".address", "is required")
+		}
+		p.address(contractField+".address", contract.Address)
+		switch strings.ToLower(contract.Standard) {
+		case "", "erc721":
+			// non-enumerable collections are found in Transfer logs, never scanned from genesis
+			if contract.FromBlock == 0 {
+				p.add(contractField+".from_block", "is required for erc721, set the block the collection was deployed at")
+			}
+		case "erc1155":
+		default:
+			p.add(contractField+".standard", "%q is not one of erc721, erc1155", contract.Standard)
+		}
+		for _, id := range contract.TokenIDs {
+			if _, ok := new(big.Int).SetString(id, 10); !ok {
+				p.add(contractField+".token_ids", "%q is not a token ID", id)
+			}
+		}
+	}
+}
+
+func (p *configProblems) drops(field string, drops []types.DropConfig) {
+	names := make(map[string]bool)
+
+	for i, drop := range drops {
+		dropField := fmt.Sprintf("%s[%d]", field, i)
+
+		if drop.Name == "" {
+			p.add(dropField+".name", "is required")
+		} else if names[drop.Name] {
+			p.add(dropField+".name", "%q is used by another drop", drop.Name)
+		}
+		names[drop.Name] = true
+
+		if drop.Contract == "" {
+			p.add(dropField+".contract", "is required")
+		}
+		p.address(dropField+".contract", drop.Contract)
+		p.address(dropField+".currency", drop.Currency)
+
+		if _, ok := new(big.Int).SetString(drop.Price, 10); drop.Price != "" && !ok {
+			p.add(dropField+".price", "%q is not an amount in wei", drop.Price)
+		}
+
+		if !drop.Quantity.IsZero() && drop.Quantity.Min <= 0 {
+			p.add(dropField+".quantity", "must be positive")
+		}
+
+		for _, hexField := range []struct{ name, value string }{
+			{"signature", drop.Signature},
+			{"data", drop.Data},
+		} {
+			if _, err := hexutil.Decode(hexField.value); hexField.value != "" && err != nil {
+				p.add(dropField+"."+hexField.name, "%q is not 0x-prefixed hex", hexField.value)
+			}
+		}
+	}
+}
+
+// ValidateConfig checks config.yaml decoded over DefaultConfig. All problems are
+// joined in the returned error, one per line.
+func ValidateConfig(config *types.Settings) error {
+	var problems configProblems
+
+	names := make([]string, 0, len(config.Networks))
+	for name := range config.Networks {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	if _, ok := config.Networks[config.Network]; config.Network != "" && !ok {
+		problems.add("network", "%q is not defined in networks (%s)", config.Network, strings.Join(names, ", "))
+	}
+	for _, name := range names {
+		problems.network("networks."+name, config.Networks[name])
+	}
+	for i, endpoint := range SplitList(config.RPCURL) {
+		problems.url(fmt.Sprintf("rpc_url[%d]", i), endpoint, "http", "https", "ws", "wss")
+	}
+	problems.drops("drops", config.Drops)
+
+	problems.required("paths.private_keys", config.Paths.PrivateKeys)
+	problems.required("paths.accounts", config.Paths.Accounts)
+	problems.required("paths.proxies", config.Paths.Proxies)
+	problems.required("state_path", config.StatePath)
+	problems.required("balances.csv_path", config.Balances.CSVPath)
+
+	problems.delay("delay_before_start", config.DelayBeforeStart.Min, config.DelayBeforeStart.Max)
+	problems.delay("delay_between_accs", config.DelayBetweenAccs.Min, config.DelayBetweenAccs.Max)
+
+	problems.nonNegative("rpc.requests_per_second", config.RPC.RequestsPerSecond)
+	problems.nonNegative("rpc.retry_attempts", config.RPC.RetryAttempts)
+	problems.nonNegative("rpc.retry_delay", config.RPC.RetryDelay)
+
+	problems.url("proxy_check.url", config.ProxyCheck.URL, "http", "https")
+	problems.nonNegative("proxy_check.interval", config.ProxyCheck.Interval)
+	problems.positive("proxy_check.timeout", config.ProxyCheck.Timeout)
+
+	switch config.Signer.Type {
+	case "local":
+	case "clef", "http":
+		if config.Signer.URL == "" {
+			problems.add("signer.url", "is required with signer type %s", config.Signer.Type)
+		}
+		// clef may also listen on an IPC path
+		if config.Signer.Type == "http" {
+			problems.url("signer.url", config.Signer.URL, "http", "https")
+		}
+	default:
+		problems.add("signer.type", "%q is not one of local, clef, http", config.Signer.Type)
+	}
+
+	problems.ether("fund.target_balance", config.Fund.TargetBalance)
+	problems.ether("fund.threshold", config.Fund.Threshold)
+	minAmount := problems.ether("fund.amount.min", config.Fund.Amount.Min)
+	maxAmount := problems.ether("fund.amount.max", config.Fund.Amount.Max)
+	if minAmount != nil && maxAmount != nil && maxAmount.Cmp(minAmount) < 0 {
+		problems.add("fund.amount", "max (%s) is less than min (%s)", config.Fund.Amount.Max, config.Fund.Amount.Min)
+	}
+
+	problems.address("sweep.to", config.Sweep.To)
+	problems.ether("sweep.min_balance", config.Sweep.MinBalance)
+
+	problems.address("transfer_nft.to", config.TransferNFT.To)
+
+	problems.positive("faucet.payout_timeout", config.Faucet.PayoutTimeout)
+	problems.nonNegative("faucet.cooldown", config.Faucet.Cooldown)
+	problems.positive("faucet.request_timeout", config.Faucet.RequestTimeout)
+
+	problems.url("capmonster_url", config.CapmonsterURL, "http", "https")
+
+	if listen := config.Metrics.Listen; listen != "" {
+		if _, _, err := net.SplitHostPort(listen); err != nil {
+			problems.add("metrics.listen", "%q is not host:port, e.g. \":9090\"", listen)
+		}
+	}
+
+	return errors.Join(problems...)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	log "github.com/sirupsen/logrus"
+
+	"main/internal"
+	"main/pkg/global"
+	"main/pkg/state"
+	"main/pkg/types"
+	"main/pkg/utils"
+)
+
+// accountSelector narrows the loaded accounts to a subset chosen on the command
+// line. Every set selector must match for an account to be kept.
+type accountSelector struct {
+	indexes      string
+	labels       string
+	groups       string
+	addresses    string
+	state        string
+	balanceBelow string
+}
+
+var selector accountSelector
+
+func (s *accountSelector) register(flags *flag.FlagSet) {
+	flags.StringVar(&s.indexes, "accounts", "", "1-based positions of accounts in the accounts file, e.g. 1-10,15")
+	flags.StringVar(&s.labels, "label", "", "comma-separated labels of accounts.yaml")
+	flags.StringVar(&s.groups, "group", "", "comma-separated groups of accounts.yaml")
+	flags.StringVar(&s.addresses, "addresses", "", "file with one address per line")
+	flags.StringVar(&s.state, "state", "",
+		"state of the account in the state file: never-claimed, claimed-unpaid, paid, failed:<module>, never-run:<module>")
+	flags.StringVar(&s.balanceBelow, "balance-below", "", "only accounts with less ETH than this")
+}
+
+func (s *accountSelector) empty() bool {
+	return *s == accountSelector{}
+}
+
+// parseIndexes turns "1-10,15" into the set of 0-based positions
+func parseIndexes(value string) (map[int]bool, error) {
+	indexes := make(map[int]bool)
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+
+		first, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil || first < 1 {
+			return nil, fmt.Errorf("invalid account index: %q", part)
+		}
+
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(strings.TrimSpace(to)); err != nil || last < first {
+				return nil, fmt.Errorf("invalid account range: %q", part)
+			}
+		}
+
+		for i := first; i <= last; i++ {
+			indexes[i-1] = true
+		}
+	}
+
+	return indexes, nil
+}
+
+// stateMatcher returns the predicate of a -state selector
+func stateMatcher(value string) (func(state.Account) bool, error) {
+	name, module, _ := strings.Cut(value, ":")
+
+	switch name {
+	case "never-claimed":
+		return func(account state.Account) bool { return account.Faucet.Status == "" }, nil
+	case state.FaucetClaimedUnpaid, state.FaucetPaid:
+		return func(account state.Account) bool { return account.Faucet.Status == name }, nil
+	case "failed", "never-run":
+		if module == "" {
+			return nil, fmt.Errorf("state %s needs a module name, e.g. %s:Faucet test tokens", name, name)
+		}
+		return func(account state.Account) bool {
+			run, ok := account.Modules[module]
+			if name == "failed" {
+				return ok && !run.Success && !run.Skipped
+			}
+			return !ok
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown state: %s", value)
+}
+
+// apply returns the accounts matching every set selector, in their original order
+func (s *accountSelector) apply(ctx context.Context, accounts []types.AccountData) ([]types.AccountData, error) {
+	var matchers []func(int, types.AccountData) bool
+
+	if s.indexes != "" {
+		indexes, err := parseIndexes(s.indexes)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, func(i int, _ types.AccountData) bool { return indexes[i] })
+	}
+
+	if labels := utils.SplitList(s.labels); len(labels) > 0 {
+		matchers = append(matchers, func(_ int, account types.AccountData) bool {
+			return slices.Contains(labels, account.Label)
+		})
+	}
+
+	if groups := utils.SplitList(s.groups); len(groups) > 0 {
+		matchers = append(matchers, func(_ int, account types.AccountData) bool {
+			return slices.Contains(groups, account.Group)
+		})
+	}
+
+	if s.addresses != "" {
+		rows, err := utils.ReadFileByRows(s.addresses)
+		if err != nil {
+			return nil, err
+		}
+
+		addresses := make(map[common.Address]bool)
+		for _, row := range rows {
+			if row = strings.TrimSpace(row); row == "" {
+				continue
+			}
+			if !common.IsHexAddress(row) {
+				return nil, fmt.Errorf("%s: invalid address %q", s.addresses, row)
+			}
+			addresses[common.HexToAddress(row)] = true
+		}
+
+		matchers = append(matchers, func(_ int, account types.AccountData) bool {
+			return addresses[account.AccountAddress]
+		})
+	}
+
+	if s.state != "" {
+		matches, err := stateMatcher(s.state)
+		if err != nil {
+			return nil, err
+		}
+
+		store, err := state.Default(global.Config.StatePath)
+		if err != nil {
+			return nil, err
+		}
+
+		matchers = append(matchers, func(_ int, account types.AccountData) bool {
+			return matches(store.Get(account.AccountAddress))
+		})
+	}
+
+	var selected []types.AccountData
+	for i, account := range accounts {
+		keep := true
+		for _, matches := range matchers {
+			if keep = matches(i, account); !keep {
+				break
+			}
+		}
+		if keep {
+			selected = append(selected, account)
+		}
+	}
+
+	// balances are fetched last, only for accounts the other selectors kept
+	if s.balanceBelow != "" {
+		return selectBalanceBelow(ctx, selected, s.balanceBelow)
+	}
+
+	return selected, nil
+}
+
+// selectBalanceBelow keeps accounts holding less ETH than limit, fetching balances in batches
+func selectBalanceBelow(ctx context.Context, accounts []types.AccountData, limit string) ([]types.AccountData, error) {
+	threshold, err := utils.ParseEther(limit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid balance-below: %w", err)
+	}
+
+	run, err := internal.CurrentRun()
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]common.Address, len(accounts))
+	for i, account := range accounts {
+		addresses[i] = account.AccountAddress
+	}
+
+	var selected []types.AccountData
+	for i, accountState := range run.LoadAccountStates(ctx, addresses) {
+		if accountState.Err != nil {
+			log.Warnf("%s | Problem with getting balance, account is not selected: %v\n",
+				accountState.Address, accountState.Err,
+			)
+			continue
+		}
+		if accountState.Balance.Cmp(threshold) < 0 {
+			selected = append(selected, accounts[i])
+		}
+	}
+
+	return selected, nil
+}